@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nullswan/llama-hackaton/internal/chat"
+	"github.com/nullswan/llama-hackaton/internal/term"
+)
+
+// requestSummary asks the model to answer the current request in prose
+// from the output of the scripts. It returns false when no summary is
+// needed.
+func (c *console) requestSummary() (bool, error) {
+	if !c.settings.UI.Summarize || c.summarized {
+		return false, nil
+	}
+	c.summarized = true
+	c.summarizing = true
+
+	prompt, err := c.renderPrompt("summary")
+	if err != nil {
+		return false, err
+	}
+
+	c.logger.Debug("Requesting summary...")
+	c.conversation.AddMessage(
+		chat.NewMessage(
+			chat.RoleUser,
+			prompt,
+		),
+	)
+
+	return true, nil
+}
+
+// printAnswer prints a prose answer of the model, rendering its Markdown
+// when the output is a terminal.
+func printAnswer(answer string) {
+	fmt.Println(term.NewMarkdown(os.Stdout).Render(answer))
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nullswan/llama-hackaton/internal/audit"
+
+	"github.com/spf13/cobra"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect the audit log of executed scripts",
+}
+
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify [path]",
+	Short: "Verify the integrity of the audit log",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runAuditVerify,
+}
+
+func runAuditVerify(_ *cobra.Command, args []string) error {
+	path, err := audit.DefaultPath()
+	if err != nil {
+		return fmt.Errorf("error locating audit log: %w", err)
+	}
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening audit log: %w", err)
+	}
+	defer file.Close()
+
+	count, err := audit.Verify(file)
+	if err != nil {
+		return fmt.Errorf("audit log verification failed: %w", err)
+	}
+
+	fmt.Printf("Audit log is valid (%d entries)\n", count)
+	return nil
+}
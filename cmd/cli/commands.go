@@ -0,0 +1,433 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nullswan/llama-hackaton/internal/chat"
+	"github.com/nullswan/llama-hackaton/internal/llama"
+	"github.com/nullswan/llama-hackaton/internal/term"
+	"github.com/nullswan/llama-hackaton/internal/tools"
+)
+
+// modelsListTimeout bounds the listing of the models completed by /model,
+// which blocks the input.
+const modelsListTimeout = 2 * time.Second
+
+// newCommands returns the slash commands of the console.
+func (c *console) newCommands() *term.Commands {
+	return term.NewCommands(
+		term.Command{
+			Name:        "help",
+			Description: "List the commands",
+			Run:         c.runHelp,
+		},
+		term.Command{
+			Name:        "exit",
+			Description: "End the session",
+			Run: func(context.Context, string) (string, error) {
+				return "", errStopped
+			},
+		},
+		term.Command{
+			Name:        "reset",
+			Description: "Start a new conversation, keeping the system prompt",
+			Run:         c.runReset,
+		},
+		term.Command{
+			Name:        "clear",
+			Description: "Clear the screen and the conversation, rendering the system prompt again",
+			Run:         c.runClear,
+		},
+		term.Command{
+			Name:        "model",
+			Usage:       "[name]",
+			Description: "Show the models, or use another one for every completion",
+			Run:         c.runModel,
+			Complete:    c.completeModel,
+		},
+		term.Command{
+			Name:        "history",
+			Description: "Print the conversation",
+			Run:         c.runHistory,
+		},
+		term.Command{
+			Name:        "save",
+			Usage:       "<file>",
+			Description: "Save the conversation as JSON",
+			Run:         c.runSave,
+		},
+		term.Command{
+			Name:        "load",
+			Usage:       "<file>",
+			Description: "Replace the conversation by a saved one",
+			Run:         c.runLoad,
+		},
+		term.Command{
+			Name:        "retry",
+			Description: "Send the last request again, discarding what followed",
+			Run:         c.runRetry,
+		},
+		term.Command{
+			Name:        "undo",
+			Description: "Remove the last request and what followed from the conversation",
+			Run:         c.runUndo,
+		},
+		term.Command{
+			Name:        "stats",
+			Description: "Show statistics about the session",
+			Run:         c.runStats,
+		},
+		term.Command{
+			Name:        "think",
+			Usage:       "[request]",
+			Description: "Use the strong model for the request",
+			Run:         c.runThink,
+		},
+		term.Command{
+			Name:        "edit",
+			Usage:       "[text]",
+			Description: "Write the message in $EDITOR, also bound to Ctrl+X Ctrl+E",
+			Run:         c.runEdit,
+		},
+		term.Command{
+			Name:        "cwd",
+			Description: "Print the working directory of the session",
+			Run: func(context.Context, string) (string, error) {
+				fmt.Println(c.session.Cwd())
+				return "", nil
+			},
+		},
+		term.Command{
+			Name:        "env",
+			Description: "Print the environment of the session",
+			Run: func(context.Context, string) (string, error) {
+				for _, kv := range c.session.Environ() {
+					fmt.Println(kv)
+				}
+				return "", nil
+			},
+		},
+	)
+}
+
+func (c *console) runHelp(context.Context, string) (string, error) {
+	fmt.Print(c.commands.Help())
+	fmt.Println(`Use """ to start and end a multiline message.`)
+	return "", nil
+}
+
+func (c *console) runReset(context.Context, string) (string, error) {
+	if _, err := c.conversation.Reset(); err != nil {
+		return "", fmt.Errorf("failed to reset conversation: %w", err)
+	}
+	c.forgetRequests()
+
+	fmt.Println("Started a new conversation.")
+	return "", nil
+}
+
+func (c *console) runClear(context.Context, string) (string, error) {
+	systemPrompt, err := c.renderSystemPrompt()
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := c.conversation.Clean(); err != nil {
+		return "", fmt.Errorf("failed to clear conversation: %w", err)
+	}
+	c.conversation.AddMessage(
+		chat.NewMessage(
+			chat.RoleSystem,
+			systemPrompt,
+		),
+	)
+	c.forgetRequests()
+
+	fmt.Print(term.ClearScreen + term.CursorReset)
+	return "", nil
+}
+
+func (c *console) runModel(ctx context.Context, name string) (string, error) {
+	if name == "" {
+		if c.router.Routed() {
+			fmt.Println("Fast model: " + c.router.Fast().GetModel())
+			fmt.Println("Strong model: " + c.router.Strong().GetModel())
+		} else {
+			fmt.Println("Model: " + c.router.Strong().GetModel())
+		}
+		if c.escalated != nil {
+			fmt.Println("Escalated to: " + c.escalated.GetModel())
+		}
+		return "", nil
+	}
+
+	fast, err := c.router.Fast().Backend().WithModel(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to switch model: %w", err)
+	}
+	strong, err := c.router.Strong().Backend().WithModel(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to switch model: %w", err)
+	}
+
+	c.router = tools.NewModelRouter(
+		c.router.Fast().WithBackend(fast),
+		c.router.Strong().WithBackend(strong),
+	)
+	c.escalated = nil
+
+	fmt.Println("Using " + name + " for every completion.")
+	return "", nil
+}
+
+// completeModel returns the local models starting like the word. The
+// models are listed once, when first completed.
+func (c *console) completeModel(word string) []string {
+	if c.models == nil {
+		ctx, cancel := context.WithTimeout(
+			context.Background(),
+			modelsListTimeout,
+		)
+		defer cancel()
+
+		// Errors are not printed over the line being edited, the models
+		// are listed again on the next tab.
+		names, err := c.listModels(ctx)
+		if err != nil {
+			return nil
+		}
+		c.models = names
+	}
+
+	var candidates []string
+	for _, name := range c.models {
+		if strings.HasPrefix(name, word) {
+			candidates = append(candidates, name)
+		}
+	}
+
+	return candidates
+}
+
+func (c *console) listModels(ctx context.Context) ([]string, error) {
+	// The server is already running, the providers started it.
+	manager, err := llama.NewModelManager(c.settings.Provider.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to ollama: %w", err)
+	}
+
+	return manager.Names(ctx)
+}
+
+func (c *console) runHistory(context.Context, string) (string, error) {
+	for _, m := range c.conversation.GetMessages() {
+		if m.Role == chat.RoleSystem {
+			continue
+		}
+		fmt.Printf("[%s] %s\n", m.Role, m.Content)
+	}
+
+	return "", nil
+}
+
+func (c *console) runSave(_ context.Context, path string) (string, error) {
+	if path == "" {
+		return "", errors.New("usage: /save <file>")
+	}
+	path = c.sessionPath(path)
+
+	data, err := json.MarshalIndent(c.conversation.GetMessages(), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode conversation: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("failed to save conversation: %w", err)
+	}
+
+	fmt.Println("Saved the conversation to " + path)
+	return "", nil
+}
+
+func (c *console) runLoad(_ context.Context, path string) (string, error) {
+	if path == "" {
+		return "", errors.New("usage: /load <file>")
+	}
+	path = c.sessionPath(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read conversation: %w", err)
+	}
+
+	var messages []chat.Message
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return "", fmt.Errorf("failed to decode conversation: %w", err)
+	}
+
+	// Conversations saved without a system prompt get the current one.
+	if len(messages) == 0 || messages[0].Role != chat.RoleSystem {
+		systemPrompt, err := c.renderSystemPrompt()
+		if err != nil {
+			return "", err
+		}
+		messages = append(
+			[]chat.Message{chat.NewMessage(chat.RoleSystem, systemPrompt)},
+			messages...,
+		)
+	}
+
+	if _, err := c.conversation.Clean(); err != nil {
+		return "", fmt.Errorf("failed to clear conversation: %w", err)
+	}
+	for _, m := range messages {
+		c.conversation.AddMessage(m)
+	}
+	c.forgetRequests()
+
+	fmt.Printf("Loaded %d messages from %s\n", len(messages), path)
+	return "", nil
+}
+
+func (c *console) runRetry(context.Context, string) (string, error) {
+	req, ok := c.popRequest()
+	if !ok {
+		fmt.Println("Nothing to retry.")
+		return "", nil
+	}
+
+	c.resending = true
+	fmt.Println(">>> " + req.Content)
+	return req.Content, nil
+}
+
+func (c *console) runUndo(context.Context, string) (string, error) {
+	if _, ok := c.popRequest(); !ok {
+		fmt.Println("Nothing to undo.")
+		return "", nil
+	}
+
+	c.lastRequest = ""
+	if n := len(c.requests); n > 0 {
+		c.lastRequest = c.requests[n-1].Content
+	}
+	c.resetAttempts()
+
+	fmt.Println("Removed the last request.")
+	return "", nil
+}
+
+func (c *console) runStats(context.Context, string) (string, error) {
+	messages := c.conversation.GetMessages()
+	chars := 0
+	for _, m := range messages {
+		chars += len(m.Content)
+	}
+
+	fmt.Printf("Conversation: %s\n", c.conversation.GetID())
+	// Four characters per token is a rough average for English text.
+	fmt.Printf("Messages: %d (~%d tokens)\n", len(messages), chars/4)
+	fmt.Printf("Requests: %d\n", len(c.requests))
+	fmt.Printf("Failed attempts: %d\n", len(c.attempts))
+	fmt.Printf("Last model: %s\n", c.textToJSON.GetModel())
+	fmt.Printf(
+		"Session: %s\n",
+		time.Since(c.startedAt).Round(time.Second),
+	)
+
+	return "", nil
+}
+
+func (c *console) runThink(_ context.Context, req string) (string, error) {
+	c.thinking = true
+	if req == "" {
+		fmt.Println("Using the strong model for this request.")
+	}
+
+	return req, nil
+}
+
+func (c *console) runEdit(_ context.Context, text string) (string, error) {
+	req, err := editMessage(text)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(req) == "" {
+		fmt.Println("Nothing to send.")
+		return "", nil
+	}
+
+	fmt.Println(">>> " + req)
+	return req, nil
+}
+
+// sessionPath resolves a path typed by the user against the working
+// directory of the session.
+func (c *console) sessionPath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(c.session.Cwd(), path)
+}
+
+// popRequest removes the last request and every message after it from
+// the conversation.
+func (c *console) popRequest() (chat.Message, bool) {
+	n := len(c.requests)
+	if n == 0 {
+		return chat.Message{}, false
+	}
+
+	req := c.requests[n-1]
+	c.requests = c.requests[:n-1]
+	c.conversation.RemoveFrom(req.ID)
+
+	return req, true
+}
+
+// forgetRequests drops the state tied to the previous conversation.
+func (c *console) forgetRequests() {
+	c.requests = nil
+	c.lastRequest = ""
+	c.resetAttempts()
+}
+
+// readRequest reads the next user input. Slash commands are handled
+// locally and only reach the model when they produce a request.
+func (c *console) readRequest(ctx context.Context) (string, error) {
+	c.resending = false
+	for {
+		line, err := c.inputHandler.Read(ctx, ">>> ")
+		if err != nil {
+			return "", fmt.Errorf("failed to read request: %w", err)
+		}
+
+		cmd, args, ok, err := c.commands.Lookup(line)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		if !ok {
+			return line, nil
+		}
+
+		req, err := cmd.Run(ctx, args)
+		if errors.Is(err, errStopped) {
+			return "", err
+		}
+		if err != nil {
+			fmt.Println("Error: " + err.Error())
+			continue
+		}
+		if strings.TrimSpace(req) != "" {
+			return req, nil
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nullswan/llama-hackaton/internal/config"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// flagKeys maps the command line flags to the config keys they override.
+var flagKeys = map[string]string{
+	"model":             "provider.model",
+	"redact":            "safety.redact",
+	"continue-on-error": "executors.continue_on_error",
+	"verify":            "safety.verify",
+	"summarize":         "ui.summarize",
+	"max-attempts":      "retry.max_attempts",
+	"escalate-after":    "retry.escalate_after",
+	"escalate-model":    "retry.escalate_model",
+	"retry-hint-after":  "retry.hint_after",
+	"temperature":       "sampling.temperature",
+	"top-p":             "sampling.top_p",
+	"num-ctx":           "sampling.num_ctx",
+	"seed":              "sampling.seed",
+	"keep-alive":        "sampling.keep_alive",
+}
+
+var configProject bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and change the configuration",
+}
+
+var configGetCmd = &cobra.Command{
+	Use:       "get <key>",
+	Short:     "Print the effective value of a key",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: config.Keys(),
+	RunE:      runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>...",
+	Short: "Set a key in the user or project configuration file",
+	Long: "Set a key in the user configuration file, or in the project one with --project.\n\nKeys:\n  " +
+		strings.Join(config.Keys(), "\n  "),
+	Args: cobra.MinimumNArgs(2),
+	RunE: runConfigSet,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration and the files it was read from",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigShow,
+}
+
+// loadSettings builds the configuration of the command: the config files
+// and environment, overridden by the flags set on the command line.
+func loadSettings(cmd *cobra.Command) (config.Config, []string, error) {
+	cfg, sources, err := config.Load()
+	if err != nil {
+		return config.Config{}, nil, fmt.Errorf("error loading config: %w", err)
+	}
+
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		key, ok := flagKeys[f.Name]
+		if !ok || err != nil {
+			return
+		}
+
+		values := []string{f.Value.String()}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			values = sv.GetSlice()
+		}
+		if setErr := config.Set(&cfg, key, values...); setErr != nil {
+			err = fmt.Errorf("error applying --%s: %w", f.Name, setErr)
+		}
+	})
+	if err != nil {
+		return config.Config{}, nil, err
+	}
+
+	return cfg, sources, nil
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	cfg, _, err := loadSettings(cmd)
+	if err != nil {
+		return err
+	}
+
+	value, err := config.Get(cfg, args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(value)
+	return nil
+}
+
+func runConfigSet(_ *cobra.Command, args []string) error {
+	path, err := config.UserPath()
+	if err != nil {
+		return err
+	}
+
+	if configProject {
+		if config.UserOnly(args[0]) {
+			return fmt.Errorf("%w: %s", config.ErrUserOnlyKey, args[0])
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("error getting working directory: %w", err)
+		}
+
+		path = config.ProjectPath(cwd)
+		if path == "" {
+			path = filepath.Join(cwd, ".nomi.toml")
+		}
+	}
+
+	if err := config.SetInFile(path, args[0], args[1:]...); err != nil {
+		return err
+	}
+
+	fmt.Printf("Set %s in %s\n", args[0], path)
+	return nil
+}
+
+func runConfigShow(cmd *cobra.Command, _ []string) error {
+	cfg, sources, err := loadSettings(cmd)
+	if err != nil {
+		return err
+	}
+
+	if len(sources) == 0 {
+		fmt.Println("# No config file found, using defaults")
+	}
+	for _, source := range sources {
+		fmt.Println("# " + source)
+	}
+
+	out, err := config.Encode(cfg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(out)
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const defaultEditor = "vi"
+
+// openInEditor opens a file in the editor of the user and waits for it
+// to be closed.
+func openInEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = defaultEditor
+	}
+
+	// The editor may come with arguments, like "code --wait".
+	args := strings.Fields(editor)
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running editor %s: %w", editor, err)
+	}
+
+	return nil
+}
+
+// editText opens a text in the editor through a temporary file, whose
+// extension lets the editor highlight it, and returns the edited text.
+func editText(text, ext string) (string, error) {
+	f, err := os.CreateTemp("", "nomi-*"+ext)
+	if err != nil {
+		return "", fmt.Errorf("error creating temporary file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		return "", fmt.Errorf("error writing temporary file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("error writing temporary file: %w", err)
+	}
+
+	if err := openInEditor(f.Name()); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", fmt.Errorf("error reading temporary file: %w", err)
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// editMessage edits a message in the editor.
+func editMessage(text string) (string, error) {
+	return editText(text, ".md")
+}
+
+// scriptExtension returns the file extension of the scripts of a language.
+func scriptExtension(language string) string {
+	switch strings.ToLower(language) {
+	case "bash", "sh", "shell", "zsh":
+		return ".sh"
+	case "python", "python3":
+		return ".py"
+	case "node", "javascript", "js":
+		return ".js"
+	case "go", "golang":
+		return ".go"
+	case "perl":
+		return ".pl"
+	case "ruby":
+		return ".rb"
+	case "powershell", "pwsh":
+		return ".ps1"
+	default:
+		return ".txt"
+	}
+}
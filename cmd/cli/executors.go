@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/nullswan/llama-hackaton/internal/code"
+)
+
+// loadCustomExecutors registers the executors of the configuration.
+func loadCustomExecutors(
+	registry *code.Registry,
+	configured []code.ExecutorSpec,
+) error {
+	for _, spec := range configured {
+		if err := registry.RegisterSpec(spec); err != nil {
+			return fmt.Errorf("error registering executor: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,660 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nullswan/llama-hackaton/internal/audit"
+	"github.com/nullswan/llama-hackaton/internal/chat"
+	"github.com/nullswan/llama-hackaton/internal/code"
+	"github.com/nullswan/llama-hackaton/internal/config"
+	"github.com/nullswan/llama-hackaton/internal/llama"
+	"github.com/nullswan/llama-hackaton/internal/probe"
+	"github.com/nullswan/llama-hackaton/internal/prompts"
+	"github.com/nullswan/llama-hackaton/internal/term"
+	"github.com/nullswan/llama-hackaton/internal/tools"
+)
+
+// errStopped is returned when the user chose to end the session.
+var errStopped = errors.New("stopped by user")
+
+// console holds the state of an interpreter session.
+type console struct {
+	selector     tools.Selector
+	logger       tools.Logger
+	router       tools.ModelRouter
+	inputHandler tools.InputHandler
+	conversation *chat.Conversation
+	auditLog     *audit.Log
+	codeRegistry *code.Registry
+	session      *code.Session
+
+	// textToJSON is the backend of the last completion.
+	textToJSON tools.TextToJSONBackend
+	// escalated, when set, replaces the routed backends after too many
+	// failed attempts.
+	escalated *tools.TextToJSONBackend
+	// thinking forces the strong model for the current request.
+	thinking bool
+	prompts  *prompts.Store
+	sampling samplingProfiles
+	// facts describe the machine, probed once at startup. The directory
+	// summary follows the session directory.
+	facts    probe.Facts
+	settings config.Config
+	// attempts are the failed attempts of the current request.
+	attempts []attempt
+
+	commands *term.Commands
+	// models caches the names of the local models, completed by /model.
+	models []string
+	// requests are the messages of the user requests of the conversation.
+	requests []chat.Message
+	// resending is set when a command sends a previous request again.
+	resending bool
+	startedAt time.Time
+
+	// lastRequest is the user request that originated the current actions.
+	lastRequest string
+	// verifications counts the verification passes of lastRequest.
+	verifications int
+	// summarized is set once a summary of lastRequest was requested.
+	summarized bool
+	// summarizing is set until the requested summary is generated.
+	summarizing bool
+}
+
+func interpreter(
+	ctx context.Context,
+	selector tools.Selector,
+	logger tools.Logger,
+	router tools.ModelRouter,
+	inputHandler tools.InputHandler,
+	conversation *chat.Conversation,
+	auditLog *audit.Log,
+	codeRegistry *code.Registry,
+	promptStore *prompts.Store,
+	sampling samplingProfiles,
+	settings config.Config,
+) error {
+	logger.Info("Starting console usecase")
+
+	session, err := code.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to start session: %w", err)
+	}
+
+	facts := probe.Probe(ctx, session.Cwd())
+	logger.Debug("Environment:\n" + facts.String())
+
+	c := &console{
+		selector:     selector,
+		logger:       logger,
+		router:       router,
+		inputHandler: inputHandler,
+		conversation: conversation,
+		auditLog:     auditLog,
+		codeRegistry: codeRegistry,
+		session:      session,
+
+		textToJSON: router.Strong(),
+		prompts:    promptStore,
+		sampling:   sampling,
+		facts:      facts,
+		settings:   settings,
+		startedAt:  time.Now(),
+	}
+	c.commands = c.newCommands()
+	c.inputHandler = inputHandler.WithCompleter(
+		term.Completers(
+			c.commands.Complete,
+			term.NewFileCompleter(c.session.Cwd),
+		),
+	)
+
+	systemPrompt, err := c.renderSystemPrompt()
+	if err != nil {
+		return err
+	}
+
+	conversation.AddMessage(
+		chat.NewMessage(
+			chat.RoleSystem,
+			systemPrompt,
+		),
+	)
+
+	err = c.run(ctx)
+	if errors.Is(err, errStopped) {
+		return nil
+	}
+
+	return err
+}
+
+func (c *console) run(ctx context.Context) error {
+	if err := c.readNewRequest(ctx); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("context done: %w", ctx.Err())
+		default:
+			consoleResp, err := c.complete(ctx)
+			if err != nil {
+				return err
+			}
+
+			var outcome actionOutcome
+			switch consoleResp.Action {
+			case consoleActionCode:
+				outcome, err = c.runCode(ctx, consoleResp)
+			case consoleActionPlan:
+				outcome, err = c.runPlan(ctx, consoleResp.Steps)
+			case consoleActionAsk:
+				fmt.Println(consoleResp.Question)
+				outcome, err = outcomeReplied, c.readReply(ctx)
+			case consoleActionDone:
+				printAnswer(consoleResp.Summary)
+				outcome = outcomeDone
+			case consoleActionAnswer:
+				printAnswer(consoleResp.Answer)
+				outcome = outcomeDone
+			default:
+				c.logger.Error(
+					"Unknown action: " + string(consoleResp.Action),
+				)
+				c.conversation.AddMessage(
+					chat.NewMessage(
+						chat.RoleUser,
+						"Unknown action, use one of the documented actions.",
+					),
+				)
+				c.recordAttempt(
+					"",
+					"unknown action "+strconv.Quote(string(consoleResp.Action)),
+				)
+				outcome = outcomeFailed
+			}
+			if err != nil {
+				return err
+			}
+
+			switch outcome {
+			case outcomeFailed:
+				exhausted, err := c.handleFailure(ctx)
+				if err != nil {
+					return err
+				}
+				if exhausted {
+					fmt.Println("Too many errors, how can I help you?")
+					if err := c.readNewRequest(ctx); err != nil {
+						return err
+					}
+				}
+			case outcomeSucceeded:
+				c.resetAttempts()
+				requested, err := c.requestVerification()
+				if err != nil {
+					return err
+				}
+				if !requested {
+					requested, err = c.requestSummary()
+					if err != nil {
+						return err
+					}
+				}
+				if requested {
+					continue
+				}
+				if err := c.askToContinue(ctx); err != nil {
+					return err
+				}
+			case outcomeDone:
+				c.resetAttempts()
+				if err := c.askToContinue(ctx); err != nil {
+					return err
+				}
+			case outcomeReplied:
+			}
+		}
+	}
+}
+
+// actionOutcome tells the main loop what to do after an action.
+type actionOutcome int
+
+const (
+	// outcomeSucceeded ends the current request.
+	outcomeSucceeded actionOutcome = iota
+	// outcomeFailed asks the model for another attempt.
+	outcomeFailed
+	// outcomeReplied sends the user reply back to the model.
+	outcomeReplied
+	// outcomeDone ends the current request without verification.
+	outcomeDone
+)
+
+// complete sends the conversation to the model and decodes its action.
+// The model and sampling are picked beforehand from the turn expected:
+// code turns go to the strong model, answers to the fast one.
+func (c *console) complete(ctx context.Context) (consoleResponse, error) {
+	codeTurn := c.codeTurn()
+	c.summarizing = false
+	backend := c.selectBackend(codeTurn)
+	resp, consoleResp, err := c.completeWith(
+		ctx,
+		backend,
+		c.sampling.of(codeTurn),
+	)
+	if err != nil {
+		return consoleResponse{}, err
+	}
+
+	c.textToJSON = backend
+	c.conversation.AddMessage(
+		chat.NewMessage(
+			chat.RoleAssistant,
+			resp,
+		),
+	)
+
+	return consoleResp, nil
+}
+
+// codeTurn reports whether the next completion is expected to write
+// code. Retries do, summaries and questions of the user are answered.
+func (c *console) codeTurn() bool {
+	switch {
+	case len(c.attempts) > 0:
+		return true
+	case c.summarizing:
+		return false
+	default:
+		return !isQuestion(c.lastRequest)
+	}
+}
+
+// selectBackend picks the backend of the next completion. The strong
+// model writes code and handles the requests the user asked to think
+// about.
+func (c *console) selectBackend(codeTurn bool) tools.TextToJSONBackend {
+	switch {
+	case c.escalated != nil:
+		return *c.escalated
+	case codeTurn || c.thinking:
+		return c.router.Strong()
+	default:
+		return c.router.Fast()
+	}
+}
+
+// questionWords start the requests asking for an answer rather than
+// for something to be done.
+var questionWords = []string{
+	"what", "why", "how", "who", "when", "where", "which",
+	"is", "are", "can", "could", "does", "do", "should", "explain",
+}
+
+// isQuestion reports whether the request is a question, which the fast
+// model answers.
+func isQuestion(req string) bool {
+	req = strings.ToLower(strings.TrimSpace(req))
+	if strings.HasSuffix(req, "?") {
+		return true
+	}
+
+	first, _, _ := strings.Cut(req, " ")
+	first = strings.TrimRight(first, ",:")
+	return slices.Contains(questionWords, first)
+}
+
+func (c *console) completeWith(
+	ctx context.Context,
+	backend tools.TextToJSONBackend,
+	options llama.GenerationOptions,
+) (string, consoleResponse, error) {
+	c.logger.Debug(
+		"Calling Llama backend (" + backend.GetModel() + ")...",
+	)
+	resp, err := backend.WithOptions(options).Do(ctx, c.conversation)
+	if err != nil {
+		return "", consoleResponse{}, fmt.Errorf(
+			"interpreter: error generating completion: %w",
+			err,
+		)
+	}
+
+	var consoleResp consoleResponse
+	if err := json.Unmarshal([]byte(resp), &consoleResp); err != nil {
+		return "", consoleResponse{}, fmt.Errorf(
+			"failed to unmarshal response: %w",
+			err,
+		)
+	}
+
+	c.logger.Debug(
+		"Received console response: " + resp,
+	)
+
+	return resp, consoleResp.restore(backend.Restore), nil
+}
+
+func (c *console) runCode(
+	ctx context.Context,
+	consoleResp consoleResponse,
+) (actionOutcome, error) {
+	// Sanitize code, add code block if necessary
+	if consoleResp.Language != "" && consoleResp.Code != "" &&
+		!strings.HasPrefix(consoleResp.Code, "```") {
+		consoleResp.Code = "```" + consoleResp.Language + "\n" + consoleResp.Code + "\n```"
+	}
+
+	plan := code.NewPlan(
+		consoleResp.Code,
+		!c.settings.Executors.ContinueOnError,
+	)
+
+	// The blocks are reviewed like the steps of a plan.
+	steps := make([]planStep, len(plan.Steps))
+	for i, block := range plan.Steps {
+		steps[i] = planStep{
+			Description: block.Description,
+			Language:    block.Language,
+			Code:        block.Code,
+		}
+	}
+	if len(steps) > 0 {
+		printScripts(steps)
+		approved, outcome, err := c.review(ctx, codeReview, steps, func() {
+			printScripts(steps)
+		})
+		if !approved {
+			return outcome, err
+		}
+		for i := range steps {
+			plan.Steps[i].Code = steps[i].Code
+		}
+	}
+
+	result := c.execute(ctx, plan, consoleResp.Stdin)
+
+	if len(result) == 0 {
+		c.logger.Info("No code blocks found")
+		c.conversation.AddMessage(
+			chat.NewMessage(
+				chat.RoleUser,
+				"No code was found in your response.",
+			),
+		)
+		c.recordAttempt(consoleResp.Code, "no code block found")
+		return outcomeFailed, nil
+	}
+
+	if err := c.recordExecutions(audit.ApprovalApproved, result); err != nil {
+		return outcomeFailed, err
+	}
+
+	containsError := false
+	for _, r := range result {
+		printExecutionResult(r)
+		if r.ExitCode != 0 {
+			containsError = true
+		}
+	}
+
+	formattedResult := code.FormatExecutionResultForLLM(result)
+	c.conversation.AddMessage(
+		chat.NewMessage(
+			chat.RoleAssistant,
+			formattedResult,
+		),
+	)
+
+	if containsError {
+		c.logger.Info("Code execution failed")
+		c.recordAttempt(consoleResp.Code, failureOf(result))
+		return outcomeFailed, nil
+	}
+
+	c.logger.Info("Code execution succeeded")
+	return outcomeSucceeded, nil
+}
+
+// execute runs the plan in the session, relaying the prompts of
+// interactive scripts to the user.
+func (c *console) execute(
+	ctx context.Context,
+	plan code.Plan,
+	stdin string,
+) []code.ExecutionResult {
+	plan.Session = c.session
+	plan.Stdin = stdin
+	plan.Input = func(prompt string, secret bool) (string, error) {
+		fmt.Println("The script is waiting for input:")
+		// Answers to scripts, like passwords, are kept out of the history.
+		input := c.inputHandler.WithoutHistory()
+		if secret {
+			input = input.WithoutEcho()
+		}
+		return input.Read(ctx, prompt)
+	}
+
+	results := c.codeRegistry.Run(plan)
+	if err := c.followDirectory(); err != nil {
+		c.logger.Error("Failed to update the system prompt: " + err.Error())
+	}
+
+	return results
+}
+
+// followDirectory describes the new working directory in the system
+// prompt after a script changed it.
+func (c *console) followDirectory() error {
+	cwd := c.session.Cwd()
+	if cwd == c.facts.Dir.Path {
+		return nil
+	}
+
+	c.facts.Dir = probe.SummarizeDir(cwd)
+	systemPrompt, err := c.renderSystemPrompt()
+	if err != nil {
+		return err
+	}
+	c.conversation.SetSystem(systemPrompt)
+
+	return nil
+}
+
+func printScripts(steps []planStep) {
+	for _, step := range steps {
+		fmt.Printf("```%s\n%s\n```\n", step.Language, step.Code)
+	}
+}
+
+func printExecutionResult(r code.ExecutionResult) {
+	fmt.Printf(
+		"Received (%d): %s\n%s\n",
+		r.ExitCode,
+		r.Stdout,
+		r.Stderr,
+	)
+}
+
+// askToContinue offers to send a new request once the current one is done.
+func (c *console) askToContinue(ctx context.Context) error {
+	if !c.selector.SelectBool(
+		"Do you want to continue?",
+		false,
+	) {
+		return errStopped
+	}
+
+	return c.readNewRequest(ctx)
+}
+
+// readNewRequest reads a new user request and adds it to the conversation.
+func (c *console) readNewRequest(ctx context.Context) error {
+	c.thinking = false
+	req, err := c.readRequest(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+
+	c.addRequest(req)
+	return nil
+}
+
+// readReply reads an answer to a question of the model. A request sent
+// again by /retry replaces the question.
+func (c *console) readReply(ctx context.Context) error {
+	req, err := c.readRequest(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+
+	if c.resending {
+		c.addRequest(req)
+		return nil
+	}
+
+	c.conversation.AddMessage(
+		chat.NewMessage(
+			chat.RoleUser,
+			req,
+		),
+	)
+
+	return nil
+}
+
+// addRequest starts handling a new user request.
+func (c *console) addRequest(req string) {
+	msg := chat.NewMessage(
+		chat.RoleUser,
+		req,
+	)
+	c.conversation.AddMessage(msg)
+	c.requests = append(c.requests, msg)
+	c.resending = false
+	c.lastRequest = req
+	c.verifications = 0
+	c.summarized = false
+	c.resetAttempts()
+}
+
+// renderSystemPrompt renders the system prompt of the console.
+func (c *console) renderSystemPrompt() (string, error) {
+	systemPrompt, err := c.prompts.RenderConsole(
+		promptVars(c.facts, c.codeRegistry.Languages(), c.session.Cwd()),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to get console instruction: %w", err)
+	}
+
+	return systemPrompt, nil
+}
+
+// renderPrompt renders a prompt about the current request. A broken
+// override is reported and replaced by the built-in prompt.
+func (c *console) renderPrompt(name string) (string, error) {
+	vars := promptVars(c.facts, c.codeRegistry.Languages(), c.session.Cwd())
+	vars.Request = c.lastRequest
+	vars.Failures = len(c.attempts)
+
+	out, err := c.prompts.Render(name, vars)
+	if err == nil {
+		return out, nil
+	}
+
+	c.logger.Error("Using the built-in prompt " + name + ": " + err.Error())
+	out, err = prompts.NewStore().Render(name, vars)
+	if err != nil {
+		return "", fmt.Errorf("failed to render prompt: %w", err)
+	}
+
+	return out, nil
+}
+
+// recordExecutions appends every execution result to the audit log.
+func (c *console) recordExecutions(
+	approval audit.Approval,
+	results []code.ExecutionResult,
+) error {
+	username := currentUsername()
+	for _, r := range results {
+		entry := audit.NewEntry(
+			c.conversation.GetID().String(),
+			username,
+			c.lastRequest,
+			c.textToJSON.GetModel(),
+			approval,
+			r,
+		)
+		if err := c.auditLog.Append(entry); err != nil {
+			return fmt.Errorf("failed to record execution: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func currentUsername() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+
+	return os.Getenv("USER")
+}
+
+type consoleResponse struct {
+	Action   consoleAction `json:"action"`
+	Question string        `json:"question"`
+	Language string        `json:"language"`
+	Code     string        `json:"code"`
+	Stdin    string        `json:"stdin"`
+	Steps    []planStep    `json:"steps"`
+	Summary  string        `json:"summary"`
+	Answer   string        `json:"answer"`
+}
+
+// restore puts the redacted secrets back in the fields of the response,
+// the completion kept in the conversation holding their placeholders.
+func (r consoleResponse) restore(restore func(string) string) consoleResponse {
+	r.Question = restore(r.Question)
+	r.Code = restore(r.Code)
+	r.Stdin = restore(r.Stdin)
+	r.Summary = restore(r.Summary)
+	r.Answer = restore(r.Answer)
+
+	steps := make([]planStep, len(r.Steps))
+	for i, step := range r.Steps {
+		step.Description = restore(step.Description)
+		step.Code = restore(step.Code)
+		step.Stdin = restore(step.Stdin)
+		steps[i] = step
+	}
+	r.Steps = steps
+
+	return r
+}
+
+type consoleAction string
+
+const (
+	consoleActionCode   consoleAction = "code"
+	consoleActionAsk    consoleAction = "ask"
+	consoleActionPlan   consoleAction = "plan"
+	consoleActionDone   consoleAction = "done"
+	consoleActionAnswer consoleAction = "answer"
+)
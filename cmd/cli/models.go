@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/dustin/go-humanize"
+	"github.com/nullswan/llama-hackaton/internal/config"
+	"github.com/nullswan/llama-hackaton/internal/llama"
+	"github.com/nullswan/llama-hackaton/internal/term"
+
+	"github.com/spf13/cobra"
+)
+
+var modelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "Manage the models of the ollama server",
+}
+
+var modelsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the local models",
+	Args:  cobra.NoArgs,
+	RunE:  runModelsList,
+}
+
+var modelsPullCmd = &cobra.Command{
+	Use:   "pull <model>",
+	Short: "Download a model",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runModelsPull,
+}
+
+var modelsRmCmd = &cobra.Command{
+	Use:   "rm <model>",
+	Short: "Remove a local model",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runModelsRm,
+}
+
+var modelsInfoCmd = &cobra.Command{
+	Use:   "info <model>",
+	Short: "Show the details of a local model",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runModelsInfo,
+}
+
+var modelsUseCmd = &cobra.Command{
+	Use:   "use <model>",
+	Short: "Set the default model",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runModelsUse,
+}
+
+// withModelManager runs fn with a manager of the ollama server.
+func withModelManager(
+	cmd *cobra.Command,
+	fn func(context.Context, *llama.ModelManager) error,
+) error {
+	settings, _, err := loadSettings(cmd)
+	if err != nil {
+		return err
+	}
+
+	manager, err := llama.LoadModelManager(settings.Provider.URL)
+	if err != nil {
+		return fmt.Errorf("error connecting to ollama: %w", err)
+	}
+	defer manager.Close()
+
+	return fn(cmd.Context(), manager)
+}
+
+func runModelsList(cmd *cobra.Command, _ []string) error {
+	settings, _, err := loadSettings(cmd)
+	if err != nil {
+		return err
+	}
+	defaultModel := settings.Provider.Model
+
+	return withModelManager(
+		cmd,
+		func(ctx context.Context, m *llama.ModelManager) error {
+			models, err := m.List(ctx)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "\tNAME\tSIZE\tPARAMS\tQUANT\tCONTEXT\tJSON\tTOOLS")
+			for _, info := range models {
+				marker := ""
+				if info.Name == defaultModel {
+					marker = "*"
+				}
+				fmt.Fprintf(
+					w,
+					"%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					marker,
+					info.Name,
+					humanize.Bytes(uint64(info.Size)),
+					info.ParameterSize,
+					info.Quantization,
+					formatContextLength(info.ContextLength),
+					yesNo(info.JSON),
+					yesNo(info.Tools),
+				)
+			}
+
+			return w.Flush()
+		},
+	)
+}
+
+func runModelsPull(cmd *cobra.Command, args []string) error {
+	return withModelManager(
+		cmd,
+		func(ctx context.Context, m *llama.ModelManager) error {
+			bar := term.NewProgressBar(os.Stdout)
+			err := m.Pull(ctx, args[0], bar.Update)
+			bar.Done()
+
+			return err
+		},
+	)
+}
+
+func runModelsRm(cmd *cobra.Command, args []string) error {
+	return withModelManager(
+		cmd,
+		func(ctx context.Context, m *llama.ModelManager) error {
+			if err := m.Remove(ctx, args[0]); err != nil {
+				return err
+			}
+
+			fmt.Printf("Removed %s\n", args[0])
+			return nil
+		},
+	)
+}
+
+func runModelsInfo(cmd *cobra.Command, args []string) error {
+	return withModelManager(
+		cmd,
+		func(ctx context.Context, m *llama.ModelManager) error {
+			info, err := m.Info(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "Name:\t%s\n", info.Name)
+			fmt.Fprintf(w, "Family:\t%s\n", info.Family)
+			fmt.Fprintf(w, "Size:\t%s\n", humanize.Bytes(uint64(info.Size)))
+			fmt.Fprintf(w, "Parameters:\t%s\n", info.ParameterSize)
+			fmt.Fprintf(w, "Quantization:\t%s\n", info.Quantization)
+			fmt.Fprintf(w, "Context length:\t%s\n", formatContextLength(info.ContextLength))
+			fmt.Fprintf(w, "JSON output:\t%s\n", yesNo(info.JSON))
+			fmt.Fprintf(w, "Tool calling:\t%s\n", yesNo(info.Tools))
+
+			return w.Flush()
+		},
+	)
+}
+
+func runModelsUse(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	err := withModelManager(
+		cmd,
+		func(ctx context.Context, m *llama.ModelManager) error {
+			info, err := m.Info(ctx, name)
+			if errors.Is(err, llama.ErrModelNotFound) {
+				return fmt.Errorf(
+					"%w, pull it first with: nomi models pull %s",
+					err,
+					name,
+				)
+			}
+			name = info.Name
+
+			return err
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	path, err := config.UserPath()
+	if err != nil {
+		return err
+	}
+
+	if err := config.SetInFile(path, "provider.model", name); err != nil {
+		return err
+	}
+
+	fmt.Printf("Default model set to %s\n", name)
+	return nil
+}
+
+func formatContextLength(length int) string {
+	if length == 0 {
+		return "-"
+	}
+
+	return strconv.Itoa(length)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
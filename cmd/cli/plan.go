@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nullswan/llama-hackaton/internal/audit"
+	"github.com/nullswan/llama-hackaton/internal/chat"
+	"github.com/nullswan/llama-hackaton/internal/code"
+)
+
+// planStep is a step of a plan action.
+type planStep struct {
+	Description string `json:"description"`
+	Language    string `json:"language"`
+	Code        string `json:"code"`
+	Stdin       string `json:"stdin"`
+}
+
+// block returns the code block of the i-th step.
+func (s planStep) block(i int) code.Block {
+	return code.Block{
+		ID:          strconv.Itoa(i + 1),
+		Language:    s.Language,
+		Code:        s.Code,
+		Description: s.Description,
+	}
+}
+
+type stepStatus string
+
+const (
+	stepPending stepStatus = " "
+	stepRunning stepStatus = "~"
+	stepDone    stepStatus = "x"
+	stepFailed  stepStatus = "!"
+)
+
+const (
+	reviewChoiceRun = iota
+	reviewChoiceEdit
+	reviewChoiceEditScripts
+	reviewChoiceCancel
+)
+
+// review describes code submitted to the user before it runs.
+type review struct {
+	// noun names the code in the messages, like "plan".
+	noun    string
+	action  consoleAction
+	choices []string
+}
+
+var (
+	planReview = review{
+		noun:    "plan",
+		action:  consoleActionPlan,
+		choices: []string{"Run", "Edit", "Edit scripts", "Cancel"},
+	}
+	codeReview = review{
+		noun:    "code",
+		action:  consoleActionCode,
+		choices: []string{"Run", "Edit", "Edit script", "Cancel"},
+	}
+)
+
+// runPlan shows the plan for approval, then executes its steps one at a
+// time. When a step fails, the model is asked for a revised plan of the
+// remaining work.
+func (c *console) runPlan(
+	ctx context.Context,
+	steps []planStep,
+) (actionOutcome, error) {
+	if len(steps) == 0 {
+		c.conversation.AddMessage(
+			chat.NewMessage(
+				chat.RoleUser,
+				"The plan has no steps.",
+			),
+		)
+		c.recordAttempt("", "empty plan")
+		return outcomeFailed, nil
+	}
+
+	statuses := make([]stepStatus, len(steps))
+	for i := range statuses {
+		statuses[i] = stepPending
+	}
+	printPlan(steps, statuses)
+
+	approved, outcome, err := c.review(ctx, planReview, steps, func() {
+		printPlan(steps, statuses)
+	})
+	if !approved {
+		return outcome, err
+	}
+
+	for i, step := range steps {
+		statuses[i] = stepRunning
+		printPlan(steps, statuses)
+
+		block := step.block(i)
+		result := c.execute(
+			ctx,
+			code.Plan{Steps: []code.Block{block}, StopOnFailure: true},
+			step.Stdin,
+		)
+
+		if err := c.recordExecutions(audit.ApprovalApproved, result); err != nil {
+			return outcomeFailed, err
+		}
+
+		failed := false
+		for _, r := range result {
+			printExecutionResult(r)
+			failed = failed || r.ExitCode != 0
+		}
+
+		c.conversation.AddMessage(
+			chat.NewMessage(
+				chat.RoleAssistant,
+				fmt.Sprintf(
+					"Step %d (%s):\n\n%s",
+					i+1,
+					step.Description,
+					code.FormatExecutionResultForLLM(result),
+				),
+			),
+		)
+
+		if failed {
+			statuses[i] = stepFailed
+			printPlan(steps, statuses)
+			c.recordAttempt(step.Code, failureOf(result))
+
+			c.conversation.AddMessage(
+				chat.NewMessage(
+					chat.RoleUser,
+					fmt.Sprintf(
+						"Step %d failed. Reply with a plan action containing only the remaining steps, revised to work around the failure.",
+						i+1,
+					),
+				),
+			)
+			return outcomeFailed, nil
+		}
+
+		statuses[i] = stepDone
+	}
+
+	printPlan(steps, statuses)
+	return outcomeSucceeded, nil
+}
+
+// review asks the user to run, revise or cancel the code of the steps,
+// which can be edited in the meantime; show prints them again after an
+// edit. When the code is not run, it is logged as rejected and the
+// outcome of the action is returned.
+func (c *console) review(
+	ctx context.Context,
+	r review,
+	steps []planStep,
+	show func(),
+) (bool, actionOutcome, error) {
+	question := "Run this " + r.noun + "?"
+
+	// Scripts are edited until the code is run, revised or cancelled.
+	choice := c.selector.Select(question, r.choices)
+	for choice == reviewChoiceEditScripts {
+		if err := c.editScripts(r, steps); err != nil {
+			fmt.Println("Error: " + err.Error())
+		}
+		show()
+		choice = c.selector.Select(question, r.choices)
+	}
+
+	if choice == reviewChoiceRun {
+		return true, outcomeSucceeded, nil
+	}
+
+	// The scripts that are revised or cancelled are logged as rejected.
+	rejected := make([]code.ExecutionResult, len(steps))
+	for i, step := range steps {
+		rejected[i] = code.ExecutionResult{Block: step.block(i)}
+	}
+	err := c.recordExecutions(audit.ApprovalRejected, rejected)
+	if err != nil {
+		return false, outcomeFailed, err
+	}
+
+	if choice == reviewChoiceEdit {
+		fmt.Println("How should the " + r.noun + " be changed?")
+		feedback, err := c.readRequest(ctx)
+		if err != nil {
+			return false, outcomeFailed, fmt.Errorf(
+				"failed to read input: %w",
+				err,
+			)
+		}
+
+		c.conversation.AddMessage(
+			chat.NewMessage(
+				chat.RoleUser,
+				fmt.Sprintf(
+					"Revise the %s and reply with a new %s action: %s",
+					r.noun,
+					r.action,
+					feedback,
+				),
+			),
+		)
+		return false, outcomeReplied, nil
+	}
+
+	c.conversation.AddMessage(
+		chat.NewMessage(
+			chat.RoleUser,
+			"I cancelled the "+r.noun+", do not run it.",
+		),
+	)
+	return false, outcomeDone, nil
+}
+
+// editScripts opens the script of each step in the editor, and tells the
+// model about the scripts that were changed.
+func (c *console) editScripts(r review, steps []planStep) error {
+	var edited strings.Builder
+	for i := range steps {
+		script, err := editText(
+			steps[i].Code,
+			scriptExtension(steps[i].Language),
+		)
+		if err != nil {
+			return err
+		}
+		if script == strings.TrimRight(steps[i].Code, "\r\n") {
+			continue
+		}
+
+		steps[i].Code = script
+		fmt.Fprintf(
+			&edited,
+			"\n\nStep %d (%s):\n```%s\n%s\n```",
+			i+1,
+			steps[i].Description,
+			steps[i].Language,
+			script,
+		)
+	}
+
+	if edited.Len() > 0 {
+		c.conversation.AddMessage(
+			chat.NewMessage(
+				chat.RoleUser,
+				"I edited the scripts of the "+r.noun+":"+edited.String(),
+			),
+		)
+	}
+
+	return nil
+}
+
+func printPlan(steps []planStep, statuses []stepStatus) {
+	fmt.Println("Plan:")
+	for i, step := range steps {
+		fmt.Printf(
+			"  [%s] %d. %s (%s)\n",
+			statuses[i],
+			i+1,
+			step.Description,
+			step.Language,
+		)
+	}
+}
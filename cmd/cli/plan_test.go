@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nullswan/llama-hackaton/internal/audit"
+	"github.com/nullswan/llama-hackaton/internal/chat"
+	"github.com/nullswan/llama-hackaton/internal/llama"
+	"github.com/nullswan/llama-hackaton/internal/tools"
+)
+
+// choiceSelector always picks the same item.
+type choiceSelector struct {
+	choice int
+}
+
+func (s choiceSelector) SelectBool(string, bool) bool {
+	return false
+}
+
+func (s choiceSelector) Select(string, []string) int {
+	return s.choice
+}
+
+func TestRunPlanCancelled(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditLog, err := audit.Open(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer auditLog.Close()
+
+	c := &console{
+		selector:     choiceSelector{choice: reviewChoiceCancel},
+		conversation: chat.NewStackedConversation(),
+		auditLog:     auditLog,
+		textToJSON: tools.NewTextToJSONBackend(
+			&llama.TextToJSONProvider{},
+			nil,
+		),
+	}
+
+	outcome, err := c.runPlan(context.Background(), []planStep{
+		{Description: "Remove the build", Language: "bash", Code: "rm -rf build"},
+		{Description: "Rebuild", Language: "bash", Code: "make"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if outcome != outcomeDone {
+		t.Errorf("Expected the request to end without verification, got %v", outcome)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Expected an audit log, got %v", err)
+	}
+	defer file.Close()
+
+	var codes []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry audit.Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if entry.Approval != audit.ApprovalRejected {
+			t.Errorf("Expected a rejected entry, got %q", entry.Approval)
+		}
+		codes = append(codes, entry.Block.Code)
+	}
+	if len(codes) != 2 || codes[0] != "rm -rf build" || codes[1] != "make" {
+		t.Errorf("Expected both steps to be logged, got %v", codes)
+	}
+}
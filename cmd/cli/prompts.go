@@ -0,0 +1,244 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"text/tabwriter"
+
+	"github.com/nullswan/llama-hackaton/internal/code"
+	"github.com/nullswan/llama-hackaton/internal/config"
+	"github.com/nullswan/llama-hackaton/internal/paths"
+	"github.com/nullswan/llama-hackaton/internal/probe"
+	"github.com/nullswan/llama-hackaton/internal/prompts"
+
+	"github.com/spf13/cobra"
+)
+
+const promptsDirName = "prompts"
+
+var promptsRender bool
+
+var promptsCmd = &cobra.Command{
+	Use:   "prompts",
+	Short: "Inspect and customize the prompts sent to the model",
+}
+
+var promptsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the prompts and where they are loaded from",
+	Args:  cobra.NoArgs,
+	RunE:  runPromptsList,
+}
+
+var promptsShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Print the template of a prompt",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPromptsShow,
+}
+
+var promptsEditCmd = &cobra.Command{
+	Use:   "edit <name>",
+	Short: "Override a prompt in the user config directory and open it in $EDITOR",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPromptsEdit,
+}
+
+// newPromptStore returns the prompts, overridable from the prompts
+// directory of the user config, then from the .nomi/prompts directory of
+// the project when safety.project_prompts is set.
+func newPromptStore(settings config.Config) (*prompts.Store, error) {
+	userDir, err := userPromptsDir()
+	if err != nil {
+		return nil, err
+	}
+	dirs := []string{userDir}
+
+	if settings.Safety.ProjectPrompts {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("error getting working directory: %w", err)
+		}
+		if project := config.ProjectPath(cwd); project != "" {
+			dirs = append(
+				dirs,
+				filepath.Join(filepath.Dir(project), ".nomi", promptsDirName),
+			)
+		}
+	}
+
+	return prompts.NewStore(dirs...), nil
+}
+
+// loadPromptStore returns the prompts of the configuration of the command.
+func loadPromptStore(cmd *cobra.Command) (*prompts.Store, error) {
+	settings, _, err := loadSettings(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPromptStore(settings)
+}
+
+func userPromptsDir() (string, error) {
+	dir, err := paths.ConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("error locating config directory: %w", err)
+	}
+
+	return filepath.Join(dir, promptsDirName), nil
+}
+
+// promptVars describes the machine to the templates.
+func promptVars(
+	facts probe.Facts,
+	languages []string,
+	cwd string,
+) prompts.Vars {
+	shell := ""
+	if facts.Shell != "" {
+		shell = filepath.Base(facts.Shell)
+	}
+
+	return prompts.Vars{
+		OS:        osDisplayName(runtime.GOOS),
+		GOOS:      runtime.GOOS,
+		Distro:    facts.Distro,
+		Shell:     shell,
+		Languages: languages,
+		Cwd:       cwd,
+		Username:  currentUsername(),
+		Facts:     facts.String(),
+	}
+}
+
+func osDisplayName(goos string) string {
+	switch goos {
+	case "darwin":
+		return "macOS"
+	case "linux":
+		return "Linux"
+	case "windows":
+		return "Windows"
+	case "freebsd":
+		return "FreeBSD"
+	default:
+		return goos
+	}
+}
+
+func runPromptsList(cmd *cobra.Command, _ []string) error {
+	store, err := loadPromptStore(cmd)
+	if err != nil {
+		return err
+	}
+
+	list, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, p := range list {
+		source := "built-in"
+		if !p.Builtin() {
+			source = p.Path
+		}
+		fmt.Fprintf(w, "%s\t%s\n", p.Name, source)
+	}
+
+	return w.Flush()
+}
+
+func runPromptsShow(cmd *cobra.Command, args []string) error {
+	store, err := loadPromptStore(cmd)
+	if err != nil {
+		return err
+	}
+
+	if !promptsRender {
+		p, err := store.Lookup(args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Print(p.Text)
+		return nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("error getting working directory: %w", err)
+	}
+	vars := promptVars(
+		probe.Probe(cmd.Context(), cwd),
+		code.NewDefaultRegistry().Languages(),
+		cwd,
+	)
+
+	var out string
+	if args[0] == "console" {
+		out, err = store.RenderConsole(vars)
+	} else {
+		out, err = store.Render(args[0], vars)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(out)
+	return nil
+}
+
+func runPromptsEdit(cmd *cobra.Command, args []string) error {
+	if filepath.Base(args[0]) != args[0] {
+		return fmt.Errorf("invalid prompt name %q", args[0])
+	}
+
+	store, err := loadPromptStore(cmd)
+	if err != nil {
+		return err
+	}
+
+	p, err := store.Lookup(args[0])
+	if err != nil && !errors.Is(err, prompts.ErrNotFound) {
+		return err
+	}
+	if errors.Is(err, prompts.ErrNotFound) {
+		// Editing a missing prompt creates a new one.
+		p = prompts.Prompt{Name: args[0]}
+	}
+
+	dir, err := userPromptsDir()
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, args[0]+".tmpl")
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("error creating prompts directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(p.Text), 0o600); err != nil {
+			return fmt.Errorf("error writing prompt: %w", err)
+		}
+	}
+
+	if err := openInEditor(path); err != nil {
+		return err
+	}
+
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading prompt: %w", err)
+	}
+	if err := store.Validate(args[0], string(text)); err != nil {
+		return fmt.Errorf("the prompt was saved but is invalid: %w", err)
+	}
+
+	fmt.Printf("Saved %s\n", path)
+	return nil
+}
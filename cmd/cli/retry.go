@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nullswan/llama-hackaton/internal/chat"
+	"github.com/nullswan/llama-hackaton/internal/code"
+)
+
+// attempt is a failed attempt at the current request.
+type attempt struct {
+	Model  string
+	Script string
+	Error  string
+}
+
+// recordAttempt remembers a failed attempt for the final report.
+func (c *console) recordAttempt(script, errMsg string) {
+	c.attempts = append(c.attempts, attempt{
+		Model:  c.textToJSON.GetModel(),
+		Script: script,
+		Error:  errMsg,
+	})
+}
+
+// handleFailure applies the retry policy after a failed attempt. It
+// returns true when the attempts are exhausted and the request must be
+// abandoned.
+func (c *console) handleFailure(ctx context.Context) (bool, error) {
+	failures := len(c.attempts)
+	if failures >= c.settings.Retry.MaxAttempts {
+		printAttemptReport(c.attempts)
+		return true, nil
+	}
+
+	if c.settings.Retry.EscalateAfter > 0 && failures == c.settings.Retry.EscalateAfter &&
+		c.settings.Retry.EscalateModel != "" &&
+		c.settings.Retry.EscalateModel != c.textToJSON.GetModel() {
+		strong := c.router.Strong()
+		backend, err := strong.Backend().
+			WithModel(ctx, c.settings.Retry.EscalateModel)
+		if err != nil {
+			return false, fmt.Errorf("failed to escalate model: %w", err)
+		}
+
+		c.logger.Info("Switching to model " + c.settings.Retry.EscalateModel)
+		escalated := strong.WithBackend(backend)
+		c.escalated = &escalated
+	}
+
+	if c.settings.Retry.HintAfter > 0 && failures >= c.settings.Retry.HintAfter {
+		hint, err := c.renderPrompt("retry_hint")
+		if err != nil {
+			return false, err
+		}
+
+		c.conversation.AddMessage(
+			chat.NewMessage(
+				chat.RoleUser,
+				hint,
+			),
+		)
+	}
+
+	return false, nil
+}
+
+// resetAttempts forgets the failed attempts and goes back to the
+// routed models.
+func (c *console) resetAttempts() {
+	c.attempts = nil
+	c.escalated = nil
+}
+
+func printAttemptReport(attempts []attempt) {
+	fmt.Printf("Giving up after %d failed attempts:\n", len(attempts))
+	for i, a := range attempts {
+		fmt.Printf("\nAttempt %d (%s):\n", i+1, a.Model)
+		if a.Script != "" {
+			fmt.Println(a.Script)
+		}
+		fmt.Println("Error: " + a.Error)
+	}
+	fmt.Println()
+}
+
+// failureOf describes why the executions failed, preferring the error
+// output of the first failing one.
+func failureOf(results []code.ExecutionResult) string {
+	for _, r := range results {
+		if r.ExitCode == 0 {
+			continue
+		}
+		if stderr := strings.TrimSpace(r.Stderr); stderr != "" {
+			return stderr
+		}
+		return "exit code " + strconv.Itoa(r.ExitCode)
+	}
+
+	return "unknown error"
+}
@@ -2,11 +2,125 @@ package main
 
 import (
 	"os"
+
+	"github.com/nullswan/llama-hackaton/internal/config"
 )
 
 func main() {
+	defaults := config.Default()
+
+	rootCmd.Flags().
+		StringP("model", "m", "", "Specify the model")
+	rootCmd.Flags().
+		StringArray(
+			"redact",
+			nil,
+			"Regular expression of secrets to redact before sending to the model",
+		)
+	rootCmd.Flags().
+		Bool(
+			"continue-on-error",
+			defaults.Executors.ContinueOnError,
+			"Keep running the remaining code blocks after one fails",
+		)
+	rootCmd.Flags().
+		Bool(
+			"verify",
+			defaults.Safety.Verify,
+			"Ask the model to verify the goal was achieved after a successful execution",
+		)
+	rootCmd.Flags().
+		Bool(
+			"summarize",
+			defaults.UI.Summarize,
+			"Ask the model to answer in prose from the output of successful scripts",
+		)
+	rootCmd.Flags().
+		Int(
+			"max-attempts",
+			defaults.Retry.MaxAttempts,
+			"Number of failed attempts after which a request is abandoned",
+		)
+	rootCmd.Flags().
+		Int(
+			"escalate-after",
+			defaults.Retry.EscalateAfter,
+			"Switch to the escalation model after that many failed attempts (0 disables)",
+		)
+	rootCmd.Flags().
+		String(
+			"escalate-model",
+			defaults.Retry.EscalateModel,
+			"Model used once --escalate-after failed attempts are reached",
+		)
 	rootCmd.Flags().
-		StringVarP(&targetModel, "model", "m", "", "Specify the model")
+		Int(
+			"retry-hint-after",
+			defaults.Retry.HintAfter,
+			"Ask the model to try a different approach after that many failed attempts (0 disables)",
+		)
+	rootCmd.Flags().
+		Float64(
+			"temperature",
+			0,
+			"Sampling temperature of every completion, overriding the per-action profiles",
+		)
+	rootCmd.Flags().
+		Float64(
+			"top-p",
+			0,
+			"Nucleus sampling threshold of every completion",
+		)
+	rootCmd.Flags().
+		Int(
+			"num-ctx",
+			0,
+			"Size of the context window in tokens",
+		)
+	rootCmd.Flags().
+		Int(
+			"seed",
+			0,
+			"Fixed sampling seed, for reproducible runs",
+		)
+	rootCmd.Flags().
+		String(
+			"keep-alive",
+			defaults.Sampling.KeepAlive,
+			"How long models stay loaded after a request (e.g. 10m, -1 to keep them loaded)",
+		)
+
+	auditCmd.AddCommand(auditVerifyCmd)
+	rootCmd.AddCommand(auditCmd)
+
+	modelsCmd.AddCommand(
+		modelsListCmd,
+		modelsPullCmd,
+		modelsRmCmd,
+		modelsInfoCmd,
+		modelsUseCmd,
+	)
+	rootCmd.AddCommand(modelsCmd)
+
+	configSetCmd.Flags().
+		BoolVar(
+			&configProject,
+			"project",
+			false,
+			"Write to the project .nomi.toml instead of the user config",
+		)
+	configCmd.AddCommand(configGetCmd, configSetCmd, configShowCmd)
+	rootCmd.AddCommand(configCmd)
+
+	promptsShowCmd.Flags().
+		BoolVar(
+			&promptsRender,
+			"render",
+			false,
+			"Render the template with the variables of this machine",
+		)
+	promptsCmd.AddCommand(promptsListCmd, promptsShowCmd, promptsEditCmd)
+	rootCmd.AddCommand(promptsCmd)
 
 	// Execute the root command
 	err := rootCmd.Execute()
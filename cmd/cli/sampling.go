@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nullswan/llama-hackaton/internal/config"
+	"github.com/nullswan/llama-hackaton/internal/llama"
+)
+
+// samplingProfiles are the generation options of the kinds of turns,
+// chosen for each completion rather than for each model.
+type samplingProfiles struct {
+	chat llama.GenerationOptions
+	code llama.GenerationOptions
+}
+
+func newSamplingProfiles(cfg config.SamplingConfig) (samplingProfiles, error) {
+	chat, err := generationOptions(cfg, cfg.Chat)
+	if err != nil {
+		return samplingProfiles{}, err
+	}
+
+	code, err := generationOptions(cfg, cfg.Code)
+	if err != nil {
+		return samplingProfiles{}, err
+	}
+
+	return samplingProfiles{chat: chat, code: code}, nil
+}
+
+// of returns the options of a code turn, or of a chat one.
+func (p samplingProfiles) of(codeTurn bool) llama.GenerationOptions {
+	if codeTurn {
+		return p.code
+	}
+
+	return p.chat
+}
+
+// generationOptions returns the options of a sampling profile. Shared
+// values, when set, apply to every profile; the profile ones are used
+// otherwise.
+func generationOptions(
+	cfg config.SamplingConfig,
+	profile config.SamplingProfile,
+) (llama.GenerationOptions, error) {
+	opts := llama.GenerationOptions{
+		Temperature: cfg.Temperature,
+		TopP:        cfg.TopP,
+		NumCtx:      cfg.NumCtx,
+		Seed:        cfg.Seed,
+	}
+	if profile.Temperature != nil && cfg.Temperature == nil {
+		opts.Temperature = profile.Temperature
+	}
+	if profile.TopP != nil && cfg.TopP == nil {
+		opts.TopP = profile.TopP
+	}
+
+	if cfg.KeepAlive != "" {
+		keepAlive, err := parseKeepAlive(cfg.KeepAlive)
+		if err != nil {
+			return llama.GenerationOptions{}, err
+		}
+		opts.KeepAlive = &keepAlive
+	}
+
+	return opts, nil
+}
+
+// parseKeepAlive accepts a duration or, like ollama, a number of
+// seconds.
+func parseKeepAlive(s string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(s); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid keep_alive %q: %w", s, err)
+	}
+
+	return d, nil
+}
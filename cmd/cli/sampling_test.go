@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/nullswan/llama-hackaton/internal/config"
+)
+
+func TestSamplingProfiles(t *testing.T) {
+	t.Parallel()
+
+	sampling, err := newSamplingProfiles(config.Default().Sampling)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		codeTurn bool
+		expected float64
+	}{
+		{name: "Chat", codeTurn: false, expected: 0.7},
+		{name: "Code", codeTurn: true, expected: 0.2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := sampling.of(tt.codeTurn).Temperature
+			if got == nil || *got != tt.expected {
+				t.Errorf("Expected temperature %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
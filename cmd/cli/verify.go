@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github.com/nullswan/llama-hackaton/internal/chat"
+)
+
+// maxVerifications bounds the verification passes of a single request,
+// so a model never satisfied with its own output cannot loop forever.
+const maxVerifications = 3
+
+// requestVerification asks the model whether the goal of the current
+// request was achieved. It returns false when no verification is needed.
+func (c *console) requestVerification() (bool, error) {
+	if !c.settings.Safety.Verify || c.verifications >= maxVerifications {
+		return false, nil
+	}
+	c.verifications++
+
+	prompt, err := c.renderPrompt("verify")
+	if err != nil {
+		return false, err
+	}
+
+	c.logger.Debug("Requesting verification...")
+	c.conversation.AddMessage(
+		chat.NewMessage(
+			chat.RoleUser,
+			prompt,
+		),
+	)
+
+	return true, nil
+}
@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nullswan/llama-hackaton/internal/code"
+)
+
+// Approval records how an execution was authorized.
+type Approval string
+
+const (
+	ApprovalApproved Approval = "approved"
+	// ApprovalRejected is used for scripts the user revised or cancelled
+	// instead of running them.
+	ApprovalRejected Approval = "rejected"
+)
+
+// Entry is a single record of the audit log.
+// PrevHash and Hash are filled by the Log when the entry is appended.
+type Entry struct {
+	Timestamp      time.Time     `json:"timestamp"`
+	User           string        `json:"user"`
+	ConversationID string        `json:"conversation_id"`
+	Request        string        `json:"request"`
+	Model          string        `json:"model"`
+	Block          code.Block    `json:"block"`
+	Approval       Approval      `json:"approval"`
+	ExitCode       int           `json:"exit_code"`
+	Duration       time.Duration `json:"duration_ns"`
+	StdoutDigest   string        `json:"stdout_sha256"`
+	StderrDigest   string        `json:"stderr_sha256"`
+	PrevHash       string        `json:"prev_hash"`
+	Hash           string        `json:"hash"`
+}
+
+// NewEntry builds an entry from an execution result.
+func NewEntry(
+	conversationID, user, request, model string,
+	approval Approval,
+	result code.ExecutionResult,
+) Entry {
+	return Entry{
+		Timestamp:      time.Now().UTC(),
+		User:           user,
+		ConversationID: conversationID,
+		Request:        request,
+		Model:          model,
+		Block:          result.Block,
+		Approval:       approval,
+		ExitCode:       result.ExitCode,
+		Duration:       result.Duration,
+		StdoutDigest:   digest(result.Stdout),
+		StderrDigest:   digest(result.Stderr),
+	}
+}
+
+// computeHash returns the hash of the entry chained to its PrevHash.
+// The Hash field itself is excluded from the computation.
+func (e Entry) computeHash() (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling entry: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func digest(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
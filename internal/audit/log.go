@@ -0,0 +1,193 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/nullswan/llama-hackaton/internal/paths"
+)
+
+const fileName = "audit.jsonl"
+
+var ErrChainBroken = errors.New("audit chain broken")
+
+// Log is an append-only, hash-chained JSON lines audit log.
+// Every entry embeds the hash of the previous one, so editing,
+// removing or reordering lines is detected by Verify.
+type Log struct {
+	mu       sync.Mutex
+	file     *os.File
+	lastHash string
+}
+
+// DefaultPath returns the location of the audit log in the data directory.
+func DefaultPath() (string, error) {
+	dir, err := paths.DataDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting data directory: %w", err)
+	}
+
+	return filepath.Join(dir, fileName), nil
+}
+
+// Open opens the audit log at path, creating it if needed, and resumes
+// the chain from its last entry.
+func Open(path string) (*Log, error) {
+	lastHash, err := readLastHash(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(
+		path,
+		os.O_CREATE|os.O_APPEND|os.O_WRONLY,
+		0o600,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error opening audit log: %w", err)
+	}
+
+	return &Log{
+		file:     file,
+		lastHash: lastHash,
+	}, nil
+}
+
+// Append chains the entry to the log and flushes it to disk.
+func (l *Log) Append(entry Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry.PrevHash = l.lastHash
+	hash, err := entry.computeHash()
+	if err != nil {
+		return err
+	}
+	entry.Hash = hash
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error marshalling entry: %w", err)
+	}
+
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("error writing audit entry: %w", err)
+	}
+
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("error syncing audit log: %w", err)
+	}
+
+	l.lastHash = hash
+	return nil
+}
+
+func (l *Log) Close() error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("error closing audit log: %w", err)
+	}
+
+	return nil
+}
+
+// Verify walks the log and checks every link of the chain.
+// It returns the number of valid entries read.
+func Verify(r io.Reader) (int, error) {
+	reader := bufio.NewReader(r)
+	prevHash := ""
+	count := 0
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			var entry Entry
+			if err := json.Unmarshal(line, &entry); err != nil {
+				return count, fmt.Errorf(
+					"%w: line %d: invalid entry: %v",
+					ErrChainBroken,
+					count+1,
+					err,
+				)
+			}
+
+			if entry.PrevHash != prevHash {
+				return count, fmt.Errorf(
+					"%w: line %d: previous hash mismatch",
+					ErrChainBroken,
+					count+1,
+				)
+			}
+
+			hash, hashErr := entry.computeHash()
+			if hashErr != nil {
+				return count, hashErr
+			}
+			if hash != entry.Hash {
+				return count, fmt.Errorf(
+					"%w: line %d: hash mismatch",
+					ErrChainBroken,
+					count+1,
+				)
+			}
+
+			prevHash = entry.Hash
+			count++
+		}
+
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+		if err != nil {
+			return count, fmt.Errorf("error reading audit log: %w", err)
+		}
+	}
+}
+
+func readLastHash(path string) (string, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error opening audit log: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var last []byte
+	for {
+		line, err := reader.ReadBytes('\n')
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			last = trimmed
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("error reading audit log: %w", err)
+		}
+	}
+
+	if last == nil {
+		return "", nil
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(last, &entry); err != nil {
+		return "", fmt.Errorf(
+			"%w: invalid last entry: %v",
+			ErrChainBroken,
+			err,
+		)
+	}
+
+	return entry.Hash, nil
+}
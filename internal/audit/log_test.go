@@ -0,0 +1,119 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nullswan/llama-hackaton/internal/code"
+)
+
+func writeEntries(t *testing.T, path string, n int) {
+	t.Helper()
+
+	log, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer log.Close()
+
+	for i := range n {
+		entry := NewEntry(
+			"conversation",
+			"user",
+			"list files",
+			"llama3.2:latest",
+			ApprovalApproved,
+			code.ExecutionResult{
+				Stdout:   strings.Repeat("a", i),
+				ExitCode: i,
+				Block:    code.Block{Language: "bash", Code: "ls"},
+			},
+		)
+		if err := log.Append(entry); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+}
+
+func TestLogChain(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), fileName)
+
+	// Reopening the log must resume the existing chain.
+	writeEntries(t, path, 2)
+	writeEntries(t, path, 3)
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open log: %v", err)
+	}
+	defer file.Close()
+
+	count, err := Verify(file)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if count != 5 {
+		t.Errorf("Verify() count = %d, want 5", count)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		tamper func(lines []string) []string
+	}{
+		{
+			name: "Edited entry",
+			tamper: func(lines []string) []string {
+				lines[1] = strings.Replace(
+					lines[1],
+					`"exit_code":1`,
+					`"exit_code":0`,
+					1,
+				)
+				return lines
+			},
+		},
+		{
+			name: "Removed entry",
+			tamper: func(lines []string) []string {
+				return append(lines[:1], lines[2:]...)
+			},
+		},
+		{
+			name: "Swapped entries",
+			tamper: func(lines []string) []string {
+				lines[0], lines[1] = lines[1], lines[0]
+				return lines
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), fileName)
+			writeEntries(t, path, 3)
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read log: %v", err)
+			}
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			tampered := strings.Join(tt.tamper(lines), "\n")
+
+			_, err = Verify(strings.NewReader(tampered))
+			if !errors.Is(err, ErrChainBroken) {
+				t.Errorf("Verify() error = %v, want %v", err, ErrChainBroken)
+			}
+		})
+	}
+}
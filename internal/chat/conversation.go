@@ -7,10 +7,15 @@ import (
 )
 
 type Conversation struct {
+	id        uuid.UUID
 	messages  []Message
 	createdAt time.Time
 }
 
+func (c *Conversation) GetID() uuid.UUID {
+	return c.id
+}
+
 func (c *Conversation) GetCreatedAt() time.Time {
 	return c.createdAt
 }
@@ -32,6 +37,33 @@ func (c *Conversation) RemoveMessage(id uuid.UUID) {
 	}
 }
 
+// RemoveFrom removes a message and every message after it. It reports
+// whether the message was found.
+func (c *Conversation) RemoveFrom(id uuid.UUID) bool {
+	for i, message := range c.messages {
+		if message.ID == id {
+			c.messages = c.messages[:i]
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetSystem replaces the content of the leading system message, or adds
+// one when the conversation starts with another message.
+func (c *Conversation) SetSystem(content string) {
+	if len(c.messages) > 0 && c.messages[0].Role == RoleSystem {
+		c.messages[0].Content = content
+		return
+	}
+
+	c.messages = append(
+		[]Message{NewMessage(RoleSystem, content)},
+		c.messages...,
+	)
+}
+
 func (c *Conversation) Reset() (*Conversation, error) {
 	conversation := NewStackedConversation()
 
@@ -45,6 +77,7 @@ func (c *Conversation) Reset() (*Conversation, error) {
 		)
 	}
 
+	c.id = conversation.GetID()
 	c.createdAt = conversation.GetCreatedAt()
 	c.messages = conversation.GetMessages()
 
@@ -54,6 +87,7 @@ func (c *Conversation) Reset() (*Conversation, error) {
 func (c *Conversation) Clean() (*Conversation, error) {
 	conversation := NewStackedConversation()
 
+	c.id = conversation.GetID()
 	c.createdAt = conversation.GetCreatedAt()
 	c.messages = conversation.GetMessages()
 
@@ -62,6 +96,7 @@ func (c *Conversation) Clean() (*Conversation, error) {
 
 func NewStackedConversation() *Conversation {
 	return &Conversation{
+		id:        uuid.New(),
 		messages:  make([]Message, 0),
 		createdAt: time.Now(),
 	}
@@ -1,35 +1,22 @@
 package code
 
-import (
-	"os/exec"
-	"strings"
-)
+import "os/exec"
 
 type BashExecutor struct{}
 
-func (be *BashExecutor) Execute(code string) ExecutionResult {
-	cmd := exec.Command("bash", "-c", code)
-	var stdout, stderr strings.Builder
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
-	exitCode := 0
-	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			exitCode = exitError.ExitCode()
-		} else {
-			exitCode = 1
-		}
+func (be *BashExecutor) Execute(req Request) ExecutionResult {
+	code := req.Code
+	if req.StateDir != "" {
+		code = shellStateTrap() + code
 	}
 
-	return ExecutionResult{
-		Stdout:   stdout.String(),
-		Stderr:   stderr.String(),
-		ExitCode: exitCode,
-	}
+	return runCommand(exec.Command("bash", "-c", code), req)
+}
+
+func (be *BashExecutor) Available() bool {
+	return binaryAvailable("bash")
 }
 
-func initBashExecutor() {
-	registerExecutor("bash", &BashExecutor{})
+func initBashExecutor(r *Registry) {
+	r.Register("bash", &BashExecutor{})
 }
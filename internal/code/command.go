@@ -0,0 +1,134 @@
+package code
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// InputMode defines how the code is handed to a command executor.
+type InputMode string
+
+const (
+	// InputModeArg passes the code as a command argument, like `bash -c`.
+	InputModeArg InputMode = "arg"
+	// InputModeStdin pipes the code to the command standard input.
+	InputModeStdin InputMode = "stdin"
+	// InputModeFile writes the code to a temporary file.
+	InputModeFile InputMode = "file"
+)
+
+const (
+	codePlaceholder = "{code}"
+	filePlaceholder = "{file}"
+)
+
+var ErrInvalidExecutorSpec = errors.New("invalid executor spec")
+
+// ExecutorSpec describes an executor defined by configuration.
+// Command is a template where {code} and {file} are replaced by the code
+// and the temporary file path. When absent, they are appended to it.
+type ExecutorSpec struct {
+	Language  string    `json:"language"  toml:"language"`
+	Command   []string  `json:"command"   toml:"command"`
+	Extension string    `json:"extension" toml:"extension,omitempty"`
+	Input     InputMode `json:"input"     toml:"input,omitempty"`
+}
+
+// CommandExecutor runs code through an arbitrary command.
+type CommandExecutor struct {
+	spec ExecutorSpec
+}
+
+func NewCommandExecutor(spec ExecutorSpec) (*CommandExecutor, error) {
+	if spec.Language == "" {
+		return nil, fmt.Errorf("%w: missing language", ErrInvalidExecutorSpec)
+	}
+
+	if len(spec.Command) == 0 {
+		return nil, fmt.Errorf(
+			"%w: missing command for %s",
+			ErrInvalidExecutorSpec,
+			spec.Language,
+		)
+	}
+
+	switch spec.Input {
+	case "":
+		spec.Input = InputModeFile
+	case InputModeArg, InputModeStdin, InputModeFile:
+	default:
+		return nil, fmt.Errorf(
+			"%w: unknown input mode %q for %s",
+			ErrInvalidExecutorSpec,
+			spec.Input,
+			spec.Language,
+		)
+	}
+
+	return &CommandExecutor{spec: spec}, nil
+}
+
+func (ce *CommandExecutor) Execute(req Request) ExecutionResult {
+	var args []string
+	var cmd *exec.Cmd
+
+	switch ce.spec.Input {
+	case InputModeArg:
+		args = expandCommand(ce.spec.Command, codePlaceholder, req.Code)
+		cmd = exec.Command(args[0], args[1:]...)
+	case InputModeStdin:
+		args = ce.spec.Command
+		cmd = exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(req.Code)
+	case InputModeFile:
+		file, err := os.CreateTemp("", "nomi-*"+ce.spec.Extension)
+		if err != nil {
+			return ExecutionResult{
+				Stderr:   "Failed to create temporary file: " + err.Error(),
+				ExitCode: 1,
+			}
+		}
+		defer os.Remove(file.Name())
+
+		_, err = file.WriteString(req.Code)
+		file.Close()
+		if err != nil {
+			return ExecutionResult{
+				Stderr:   "Failed to write temporary file: " + err.Error(),
+				ExitCode: 1,
+			}
+		}
+
+		args = expandCommand(ce.spec.Command, filePlaceholder, file.Name())
+		cmd = exec.Command(args[0], args[1:]...)
+	}
+
+	return runCommand(cmd, req)
+}
+
+func (ce *CommandExecutor) Available() bool {
+	return binaryAvailable(ce.spec.Command[0])
+}
+
+// expandCommand replaces the placeholder in the command template,
+// appending the value when the template does not reference it.
+func expandCommand(command []string, placeholder, value string) []string {
+	args := make([]string, 0, len(command)+1)
+	found := false
+	for _, arg := range command {
+		if strings.Contains(arg, placeholder) {
+			found = true
+			arg = strings.ReplaceAll(arg, placeholder, value)
+		}
+		args = append(args, arg)
+	}
+
+	if !found {
+		args = append(args, value)
+	}
+
+	return args
+}
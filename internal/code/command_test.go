@@ -0,0 +1,90 @@
+package code
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCommandExecutor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		spec     ExecutorSpec
+		expected string
+	}{
+		{
+			name: "Code as argument",
+			spec: ExecutorSpec{
+				Language: "custom",
+				Command:  []string{"sh", "-c", "{code}"},
+				Input:    InputModeArg,
+			},
+			expected: "hello\n",
+		},
+		{
+			name: "Code on stdin",
+			spec: ExecutorSpec{
+				Language: "custom",
+				Command:  []string{"sh", "-s"},
+				Input:    InputModeStdin,
+			},
+			expected: "hello\n",
+		},
+		{
+			name: "Code in a temporary file",
+			spec: ExecutorSpec{
+				Language:  "custom",
+				Command:   []string{"sh"},
+				Extension: ".sh",
+			},
+			expected: "hello\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			registry := NewRegistry()
+			if err := registry.RegisterSpec(tt.spec); err != nil {
+				t.Fatalf("RegisterSpec() error = %v", err)
+			}
+
+			result := registry.Execute(
+				Block{Language: "custom", Code: "echo hello"},
+			)
+			if result.ExitCode != 0 || result.Stdout != tt.expected {
+				t.Errorf(
+					"Execute() = %q (exit %d, stderr %q), want %q",
+					result.Stdout,
+					result.ExitCode,
+					result.Stderr,
+					tt.expected,
+				)
+			}
+		})
+	}
+}
+
+func TestNewCommandExecutorInvalidSpec(t *testing.T) {
+	t.Parallel()
+
+	specs := []ExecutorSpec{
+		{Command: []string{"deno", "run"}},
+		{Language: "deno"},
+		{Language: "deno", Command: []string{"deno"}, Input: "pipe"},
+	}
+
+	for _, spec := range specs {
+		_, err := NewCommandExecutor(spec)
+		if !errors.Is(err, ErrInvalidExecutorSpec) {
+			t.Errorf(
+				"NewCommandExecutor(%v) error = %v, want %v",
+				spec,
+				err,
+				ErrInvalidExecutorSpec,
+			)
+		}
+	}
+}
@@ -1,29 +1,150 @@
 package code
 
 import (
+	"errors"
+	"os"
+	"os/exec"
 	"runtime"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
-var (
-	executors                = make(map[string]Executor)
-	onceExecutorRegistration sync.Once
-)
+// languageAliases maps common names used by models to registered languages.
+var languageAliases = map[string]string{
+	"python3":    "python",
+	"py":         "python",
+	"javascript": "node",
+	"js":         "node",
+	"nodejs":     "node",
+	"golang":     "go",
+	"shell":      "sh",
+	"pwsh":       "powershell",
+	"rb":         "ruby",
+	"pl":         "perl",
+}
+
+// availabilityChecker is implemented by executors relying on an external
+// binary that may not be installed.
+type availabilityChecker interface {
+	Available() bool
+}
+
+// Registry maps languages to the executors able to run them.
+type Registry struct {
+	mu        sync.RWMutex
+	executors map[string]Executor
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		executors: make(map[string]Executor),
+	}
+}
+
+// NewDefaultRegistry returns a registry holding the built-in executors.
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+
+	initBashExecutor(r)
+	initPythonExecutor(r)
+	initOsascriptExecutor(r)
+	initPowershellExecutor(r)
+	initNodeExecutor(r)
+	initRubyExecutor(r)
+	initPerlExecutor(r)
+	initShExecutor(r)
+	initZshExecutor(r)
+	initGoExecutor(r)
+
+	return r
+}
+
+// Register adds or replaces the executor of a language.
+func (r *Registry) Register(language string, executor Executor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.executors[strings.ToLower(language)] = executor
+}
+
+// RegisterSpec registers a command executor described by a spec.
+func (r *Registry) RegisterSpec(spec ExecutorSpec) error {
+	executor, err := NewCommandExecutor(spec)
+	if err != nil {
+		return err
+	}
 
-func registerExecutor(language string, executor Executor) {
-	executors[language] = executor
+	r.Register(spec.Language, executor)
+	return nil
 }
 
-func ExecuteCodeBlock(block Block) ExecutionResult {
-	onceExecutorRegistration.Do(
-		func() {
-			initBashExecutor()
-			initPythonExecutor()
-			initOsascriptExecutor()
-		},
-	)
+// Languages returns the sorted list of languages that can be executed
+// on this machine.
+func (r *Registry) Languages() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
-	executor, ok := executors[block.Language]
+	languages := make([]string, 0, len(r.executors))
+	for language, executor := range r.executors {
+		if !supportedOnOS(language) {
+			continue
+		}
+		if checker, ok := executor.(availabilityChecker); ok &&
+			!checker.Available() {
+			continue
+		}
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	return languages
+}
+
+func (r *Registry) lookup(language string) (Executor, string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	language = strings.ToLower(strings.TrimSpace(language))
+	if executor, ok := r.executors[language]; ok {
+		return executor, language, true
+	}
+
+	if alias, ok := languageAliases[language]; ok {
+		executor, ok := r.executors[alias]
+		return executor, alias, ok
+	}
+
+	return nil, language, false
+}
+
+func supportedOnOS(language string) bool {
+	switch language {
+	case "osascript":
+		return runtime.GOOS == "darwin"
+	case "powershell":
+		return runtime.GOOS == "windows" || binaryAvailable("pwsh")
+	default:
+		return true
+	}
+}
+
+// Execute runs the block with the executor registered for its language.
+func (r *Registry) Execute(block Block) ExecutionResult {
+	return r.execute(block, nil, Plan{})
+}
+
+// execute runs a step of the plan with extra environment variables.
+// When the plan has a session, the block starts from its state and
+// updates it on exit.
+func (r *Registry) execute(
+	block Block,
+	env []string,
+	plan Plan,
+) ExecutionResult {
+	executor, language, ok := r.lookup(block.Language)
 	if !ok {
 		return ExecutionResult{
 			Stderr:   "Unsupported language: " + block.Language,
@@ -31,22 +152,123 @@ func ExecuteCodeBlock(block Block) ExecutionResult {
 		}
 	}
 
-	if block.Language == "osascript" && runtime.GOOS != "darwin" {
+	if language == "osascript" && runtime.GOOS != "darwin" {
 		return ExecutionResult{
 			Stderr:   "Osascript is only supported on macOS",
 			ExitCode: 1,
 		}
 	}
 
-	if block.Language == "powershell" && runtime.GOOS != "windows" {
+	// Outside of Windows, powershell runs with pwsh, the cross-platform
+	// PowerShell.
+	if language == "powershell" && !supportedOnOS(language) {
 		return ExecutionResult{
-			Stderr:   "Powershell is only supported on Windows",
+			Stderr:   "Powershell needs pwsh, which was not found in PATH",
 			ExitCode: 1,
 		}
 	}
 
-	r := executor.Execute(block.Code)
-	r.Block = block
+	if checker, ok := executor.(availabilityChecker); ok &&
+		!checker.Available() {
+		return ExecutionResult{
+			Stderr:   "Language is not installed on this machine: " + language,
+			ExitCode: 1,
+		}
+	}
 
-	return r
+	req := Request{
+		Code:  block.Code,
+		Stdin: plan.Stdin,
+		Input: plan.Input,
+	}
+	session := plan.Session
+	if session != nil {
+		stateDir, err := os.MkdirTemp("", "nomi-state-*")
+		if err != nil {
+			return ExecutionResult{
+				Stderr:   "Failed to create state directory: " + err.Error(),
+				ExitCode: 1,
+				Block:    block,
+			}
+		}
+		defer os.RemoveAll(stateDir)
+
+		req.Dir = session.Cwd()
+		req.StateDir = stateDir
+		req.Env = append(session.Environ(), stateDirEnv+"="+stateDir)
+	}
+	if len(env) > 0 {
+		if req.Env == nil {
+			req.Env = os.Environ()
+		}
+		req.Env = append(req.Env, env...)
+	}
+
+	start := time.Now()
+	res := executor.Execute(req)
+	res.Duration = time.Since(start)
+	res.Block = block
+
+	if session != nil {
+		if err := session.capture(req.StateDir); err != nil {
+			res.Stderr += "\n" + err.Error()
+		}
+	}
+
+	return res
+}
+
+func runCommand(cmd *exec.Cmd, req Request) ExecutionResult {
+	if req.Env != nil {
+		cmd.Env = req.Env
+	}
+	if req.Dir != "" && cmd.Dir == "" {
+		cmd.Dir = req.Dir
+	}
+
+	// Scripts given a standard input read it from a pipe, which ends it.
+	if req.Input != nil && req.Stdin == "" {
+		return runInteractive(cmd, req)
+	}
+
+	return runPiped(cmd, req)
+}
+
+func runPiped(cmd *exec.Cmd, req Request) ExecutionResult {
+	if req.Stdin != "" && cmd.Stdin == nil {
+		cmd.Stdin = strings.NewReader(req.Stdin)
+	}
+
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+
+	return ExecutionResult{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		ExitCode: exitCode(err, &stderr),
+	}
+}
+
+// exitCode returns the exit code of a finished command. Errors not coming
+// from the process itself are reported on stderr.
+func exitCode(err error, stderr *strings.Builder) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		return exitError.ExitCode()
+	}
+
+	stderr.WriteString(err.Error())
+	return 1
+}
+
+func binaryAvailable(binary string) bool {
+	_, err := exec.LookPath(binary)
+	return err == nil
 }
@@ -1,8 +1,11 @@
 package code
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -13,7 +16,7 @@ type MockExecutor struct {
 	code   int
 }
 
-func (m *MockExecutor) Execute(_ string) ExecutionResult {
+func (m *MockExecutor) Execute(_ Request) ExecutionResult {
 	return ExecutionResult{
 		Stdout:   m.output,
 		Stderr:   m.err,
@@ -21,18 +24,22 @@ func (m *MockExecutor) Execute(_ string) ExecutionResult {
 	}
 }
 
-func TestExecuteCodeBlock(t *testing.T) {
+func TestRegistryExecute(t *testing.T) {
 	t.Parallel()
 
-	// Register mock executors
-	registerExecutor(
+	registry := NewRegistry()
+	registry.Register(
 		"mock",
 		&MockExecutor{output: "Mock output", err: "", code: 0},
 	)
-	registerExecutor(
+	registry.Register(
 		"error",
 		&MockExecutor{output: "", err: "Mock error", code: 1},
 	)
+	registry.Register(
+		"python",
+		&MockExecutor{output: "Python output", err: "", code: 0},
+	)
 
 	tests := []struct {
 		name     string
@@ -46,6 +53,7 @@ func TestExecuteCodeBlock(t *testing.T) {
 				Stdout:   "Mock output",
 				Stderr:   "",
 				ExitCode: 0,
+				Block:    Block{Language: "mock", Code: "test code"},
 			},
 		},
 		{
@@ -55,6 +63,17 @@ func TestExecuteCodeBlock(t *testing.T) {
 				Stdout:   "",
 				Stderr:   "Mock error",
 				ExitCode: 1,
+				Block:    Block{Language: "error", Code: "test code"},
+			},
+		},
+		{
+			name:  "Language alias",
+			block: Block{Language: "python3", Code: "test code"},
+			expected: ExecutionResult{
+				Stdout:   "Python output",
+				Stderr:   "",
+				ExitCode: 0,
+				Block:    Block{Language: "python3", Code: "test code"},
 			},
 		},
 		{
@@ -72,10 +91,11 @@ func TestExecuteCodeBlock(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			result := ExecuteCodeBlock(tt.block)
+			result := registry.Execute(tt.block)
+			result.Duration = 0
 			if !reflect.DeepEqual(result, tt.expected) {
 				t.Errorf(
-					"ExecuteCodeBlock() = %v, want %v",
+					"Execute() = %v, want %v",
 					result,
 					tt.expected,
 				)
@@ -87,13 +107,14 @@ func TestExecuteCodeBlock(t *testing.T) {
 func TestOsascriptExecution(t *testing.T) {
 	t.Parallel()
 
-	registerExecutor(
+	registry := NewRegistry()
+	registry.Register(
 		"osascript",
 		&MockExecutor{output: "Osascript output", err: "", code: 0},
 	)
 
 	block := Block{Language: "osascript", Code: "test code"}
-	result := ExecuteCodeBlock(block)
+	result := registry.Execute(block)
 
 	if runtime.GOOS == "darwin" {
 		if result.Stdout != "Osascript output" {
@@ -105,3 +126,128 @@ func TestOsascriptExecution(t *testing.T) {
 		}
 	}
 }
+
+// fakeBinaries makes PATH hold only scripts named after the binaries,
+// printing their name and arguments.
+func fakeBinaries(t *testing.T, binaries ...string) {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("Fake binaries are shell scripts")
+	}
+
+	dir := t.TempDir()
+	for _, binary := range binaries {
+		script := "#!/bin/sh\necho " + binary + " \"$@\"\n"
+		path := filepath.Join(dir, binary)
+		if err := os.WriteFile(path, []byte(script), 0o700); err != nil {
+			t.Fatalf("Failed to write %s: %v", binary, err)
+		}
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestExecutorsAvailable(t *testing.T) {
+	tests := []struct {
+		language string
+		binary   string
+		expected string
+	}{
+		{language: "bash", binary: "bash", expected: "bash -c echo"},
+		{language: "sh", binary: "sh", expected: "sh -c echo"},
+		{language: "zsh", binary: "zsh", expected: "zsh -c echo"},
+		{language: "python", binary: "python3", expected: "python3 -c echo"},
+		{language: "node", binary: "node", expected: "node -e echo"},
+		{language: "ruby", binary: "ruby", expected: "ruby -e echo"},
+		{language: "perl", binary: "perl", expected: "perl -e echo"},
+		{
+			language: "powershell",
+			binary:   "pwsh",
+			expected: "pwsh -NoProfile -NonInteractive -Command echo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			fakeBinaries(t)
+			registry := NewDefaultRegistry()
+			if slices.Contains(registry.Languages(), tt.language) {
+				t.Errorf("Expected %s to be unavailable without %s", tt.language, tt.binary)
+			}
+			result := registry.Execute(Block{Language: tt.language, Code: "echo"})
+			if result.ExitCode == 0 || result.Stderr == "" {
+				t.Errorf("Expected an error without %s, got %+v", tt.binary, result)
+			}
+
+			fakeBinaries(t, tt.binary)
+			registry = NewDefaultRegistry()
+			if !slices.Contains(registry.Languages(), tt.language) {
+				t.Errorf("Expected %s to be available with %s", tt.language, tt.binary)
+			}
+			result = registry.Execute(Block{Language: tt.language, Code: "echo"})
+			if got := strings.TrimSpace(result.Stdout); got != tt.expected {
+				t.Errorf("Expected %q, got %q (%s)", tt.expected, got, result.Stderr)
+			}
+		})
+	}
+}
+
+func TestPowershellWithoutPwsh(t *testing.T) {
+	fakeBinaries(t, "powershell")
+
+	result := NewDefaultRegistry().Execute(Block{Language: "powershell", Code: "echo"})
+	if result.ExitCode == 0 {
+		t.Fatalf("Expected an error, got %+v", result)
+	}
+	if !strings.Contains(result.Stderr, "pwsh") ||
+		strings.Contains(result.Stderr, "Windows") {
+		t.Errorf("Expected an error about pwsh, got %q", result.Stderr)
+	}
+}
+
+func TestGoExecutorDir(t *testing.T) {
+	t.Parallel()
+
+	if !binaryAvailable("go") {
+		t.Skip("go is not installed")
+	}
+
+	dir := t.TempDir()
+	result := (&GoExecutor{}).Execute(Request{
+		Code: `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	dir, _ := os.Getwd()
+	fmt.Println(dir)
+}
+`,
+		Dir: dir,
+	})
+
+	want, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	got, err := filepath.EvalSymlinks(strings.TrimSpace(result.Stdout))
+	if err != nil || got != want {
+		t.Errorf("Expected the program to run in %q, got %+v", want, result)
+	}
+}
+
+func TestGoExecutorBuildError(t *testing.T) {
+	t.Parallel()
+
+	if !binaryAvailable("go") {
+		t.Skip("go is not installed")
+	}
+
+	result := (&GoExecutor{}).Execute(Request{Code: "package main\n\nfunc main() {"})
+	if result.ExitCode == 0 || result.Stderr == "" {
+		t.Errorf("Expected a build error, got %+v", result)
+	}
+}
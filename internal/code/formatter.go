@@ -2,9 +2,16 @@ package code
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 )
 
+// escapePattern matches the control sequences of terminals, like colors
+// and cursor moves, and the titles set by programs.
+var escapePattern = regexp.MustCompile(
+	`\x1b(\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(\x07|\x1b\\)|[@-Z\\-_])`,
+)
+
 func FormatExecutionResultForLLM(results []ExecutionResult) string {
 	var sections []string
 
@@ -19,14 +26,14 @@ func FormatExecutionResultForLLM(results []ExecutionResult) string {
 		if r.Stderr != "" {
 			sectionParts = append(
 				sectionParts,
-				"Error:\n"+r.Stderr,
+				"Error:\n"+cleanOutput(r.Stderr),
 			)
 		}
 
 		if r.Stdout != "" {
 			sectionParts = append(
 				sectionParts,
-				"Output:\n"+r.Stdout,
+				"Output:\n"+cleanOutput(r.Stdout),
 			)
 		}
 
@@ -44,3 +51,24 @@ func FormatExecutionResultForLLM(results []ExecutionResult) string {
 
 	return strings.Join(sections, "\n\n"+strings.Repeat("-", 40)+"\n\n")
 }
+
+// cleanOutput removes the escape sequences of the output and keeps the
+// last state of the lines redrawn with carriage returns, like progress
+// bars, leaving the text as it was last seen on a terminal.
+func cleanOutput(s string) string {
+	s = escapePattern.ReplaceAllString(s, "")
+	if !strings.Contains(s, "\r") {
+		return s
+	}
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if j := strings.LastIndexByte(line, '\r'); j >= 0 {
+			line = line[j+1:]
+		}
+		lines[i] = line
+	}
+
+	return strings.Join(lines, "\n")
+}
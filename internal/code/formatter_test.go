@@ -70,6 +70,26 @@ Third error
 
 Output:
 Third output`,
+		},
+		{
+			name: "Terminal output",
+			results: []ExecutionResult{
+				{
+					Stdout: "\x1b]0;title\x07\x1b[1;32mok\x1b[0m\n" +
+						"  0%\r 50%\r100%\r\ndone\r\n",
+					Stderr: "\x1b[31merror\x1b[m",
+				},
+			},
+			expected: `--- Execution Result 1 ---
+
+Error:
+error
+
+Output:
+ok
+100%
+done
+`,
 		},
 		{
 			name:     "Empty results",
@@ -0,0 +1,70 @@
+package code
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const goModule = "module nomiscript\n"
+
+// GoExecutor builds a main package in a throwaway module and runs it.
+type GoExecutor struct{}
+
+func (ge *GoExecutor) Execute(req Request) ExecutionResult {
+	dir, err := os.MkdirTemp("", "nomi-go-*")
+	if err != nil {
+		return ExecutionResult{
+			Stderr:   "Failed to create temporary module: " + err.Error(),
+			ExitCode: 1,
+		}
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"go.mod":  goModule,
+		"main.go": req.Code,
+	}
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600)
+		if err != nil {
+			return ExecutionResult{
+				Stderr:   "Failed to write temporary module: " + err.Error(),
+				ExitCode: 1,
+			}
+		}
+	}
+
+	// The program is built in the module, then runs from the directory of
+	// the request like the scripts of other languages.
+	binary := filepath.Join(dir, "main")
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+	build := exec.Command("go", "build", "-o", binary, ".")
+	build.Dir = dir
+	build.Env = req.Env
+
+	var output strings.Builder
+	build.Stdout = &output
+	build.Stderr = &output
+	if err := build.Run(); err != nil {
+		code := exitCode(err, &output)
+		return ExecutionResult{
+			Stderr:   output.String(),
+			ExitCode: code,
+		}
+	}
+
+	return runCommand(exec.Command(binary), req)
+}
+
+func (ge *GoExecutor) Available() bool {
+	return binaryAvailable("go")
+}
+
+func initGoExecutor(r *Registry) {
+	r.Register("go", &GoExecutor{})
+}
@@ -1,12 +1,116 @@
 package code
 
-func InterpretCodeBlocks(input string) []ExecutionResult {
-	blocks := ParseCodeBlocks(input)
-	results := make([]ExecutionResult, len(blocks))
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
 
-	for i, block := range blocks {
-		results[i] = ExecuteCodeBlock(block)
+const envPrefix = "NOMI_"
+
+// Plan is an ordered list of blocks to execute.
+type Plan struct {
+	Steps []Block
+	// StopOnFailure aborts the plan after the first failing step.
+	StopOnFailure bool
+	// Session, when set, carries the working directory and environment
+	// from one step to the next and across plans.
+	Session *Session
+	// Stdin is fed to the standard input of every step.
+	Stdin string
+	// Input answers the prompts of steps waiting for input.
+	Input InputFunc
+}
+
+// NewPlan builds a plan from the code blocks of the input.
+func NewPlan(input string, stopOnFailure bool) Plan {
+	return Plan{
+		Steps:         ParseCodeBlocks(input),
+		StopOnFailure: stopOnFailure,
+	}
+}
+
+// Interpret parses the code blocks of the input and executes them all.
+func (r *Registry) Interpret(input string) []ExecutionResult {
+	return r.Run(NewPlan(input, false))
+}
+
+// Run executes the steps of the plan in order, skipping the ones marked
+// with the skip attribute. The outputs of previous steps are exposed to
+// the next ones through environment variables:
+//
+//	NOMI_PREV_STDOUT, NOMI_PREV_EXIT_CODE
+//	NOMI_STEP_<n>_STDOUT, NOMI_STEP_<n>_EXIT_CODE
+//	NOMI_<NAME>_STDOUT, NOMI_<NAME>_EXIT_CODE for steps with a name
+//
+// The outputs are truncated to maxEnvValue bytes.
+func (r *Registry) Run(plan Plan) []ExecutionResult {
+	results := make([]ExecutionResult, 0, len(plan.Steps))
+	var env []string
+
+	for i, block := range plan.Steps {
+		if block.Skipped() {
+			continue
+		}
+
+		result := r.execute(block, env, plan)
+		results = append(results, result)
+
+		if result.ExitCode != 0 && plan.StopOnFailure {
+			break
+		}
+
+		env = setEnv(env, stepEnv("STEP_"+strconv.Itoa(i+1), result)...)
+		if name := block.Name(); name != "" {
+			env = setEnv(env, stepEnv(envName(name), result)...)
+		}
+		env = setEnv(env, stepEnv("PREV", result)...)
 	}
 
 	return results
 }
+
+// maxEnvValue bounds the outputs exposed to the next steps, the size of
+// the environment of a process being limited.
+const maxEnvValue = 16 * 1024
+
+func stepEnv(key string, result ExecutionResult) []string {
+	stdout := strings.TrimRight(result.Stdout, "\n")
+	if len(stdout) > maxEnvValue {
+		stdout = strings.ToValidUTF8(stdout[:maxEnvValue], "")
+	}
+
+	return []string{
+		envPrefix + key + "_STDOUT=" + stdout,
+		envPrefix + key + "_EXIT_CODE=" + strconv.Itoa(result.ExitCode),
+	}
+}
+
+// setEnv sets the variables in env, replacing the previous values of the
+// same names.
+func setEnv(env []string, vars ...string) []string {
+	for _, v := range vars {
+		name, _, _ := strings.Cut(v, "=")
+		i := slices.IndexFunc(env, func(e string) bool {
+			return strings.HasPrefix(e, name+"=")
+		})
+		if i >= 0 {
+			env[i] = v
+		} else {
+			env = append(env, v)
+		}
+	}
+
+	return env
+}
+
+// envName turns a step name into a valid environment variable name.
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, name)
+}
@@ -2,31 +2,170 @@ package code
 
 import (
 	"reflect"
+	"slices"
+	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
-func TestInterpretCodeBlocks(t *testing.T) {
+// envExecutor echoes the environment it received.
+type envExecutor struct {
+	received [][]string
+}
+
+func (e *envExecutor) Execute(req Request) ExecutionResult {
+	e.received = append(e.received, req.Env)
+	return ExecutionResult{Stdout: req.Code + "\n"}
+}
+
+func TestRegistryInterpret(t *testing.T) {
 	t.Parallel()
 
-	// Register mock executors
-	registerExecutor(
+	registry := NewRegistry()
+	registry.Register(
 		"python",
 		&MockExecutor{output: "Python output", err: "", code: 0},
 	)
-	registerExecutor(
+	registry.Register(
 		"bash",
 		&MockExecutor{output: "Bash output", err: "", code: 0},
 	)
 
 	input := "```python\nprint('Hello')\n```\n```bash\necho 'World'\n```"
 	expected := []ExecutionResult{
-		{Stdout: "Python output", Stderr: "", ExitCode: 0},
-		{Stdout: "Bash output", Stderr: "", ExitCode: 0},
+		{
+			Stdout:   "Python output",
+			Stderr:   "",
+			ExitCode: 0,
+			Block:    Block{Language: "python", Code: "print('Hello')"},
+		},
+		{
+			Stdout:   "Bash output",
+			Stderr:   "",
+			ExitCode: 0,
+			Block:    Block{Language: "bash", Code: "echo 'World'"},
+		},
 	}
 
-	results := InterpretCodeBlocks(input)
+	results := registry.Interpret(input)
+	for i := range results {
+		results[i].Duration = 0
+	}
 
 	if !reflect.DeepEqual(results, expected) {
-		t.Errorf("InterpretCodeBlocks() = %v, want %v", results, expected)
+		t.Errorf("Interpret() = %v, want %v", results, expected)
+	}
+}
+
+func TestRegistryRun(t *testing.T) {
+	t.Parallel()
+
+	input := "```ok\nfirst\n```\n" +
+		"```ok {skip}\nskipped\n```\n" +
+		"```error\nsecond\n```\n" +
+		"```ok\nthird\n```"
+
+	tests := []struct {
+		name          string
+		stopOnFailure bool
+		expected      []string
+	}{
+		{
+			name:          "Continue after failure",
+			stopOnFailure: false,
+			expected:      []string{"first", "second", "third"},
+		},
+		{
+			name:          "Stop on first failure",
+			stopOnFailure: true,
+			expected:      []string{"first", "second"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			registry := NewRegistry()
+			registry.Register("ok", &MockExecutor{output: "ok"})
+			registry.Register("error", &MockExecutor{err: "ko", code: 1})
+
+			results := registry.Run(NewPlan(input, tt.stopOnFailure))
+
+			codes := make([]string, len(results))
+			for i, r := range results {
+				codes[i] = r.Block.Code
+			}
+			if !slices.Equal(codes, tt.expected) {
+				t.Errorf("Run() executed %v, want %v", codes, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRegistryRunEnv(t *testing.T) {
+	t.Parallel()
+
+	executor := &envExecutor{}
+	registry := NewRegistry()
+	registry.Register("env", executor)
+
+	input := "```env {name=list-files}\nfiles\n```\n```env\ncount\n```"
+	registry.Run(NewPlan(input, true))
+
+	if len(executor.received) != 2 {
+		t.Fatalf("expected 2 executions, got %d", len(executor.received))
+	}
+
+	if len(executor.received[0]) != 0 {
+		t.Errorf("first step env = %v, want empty", executor.received[0])
+	}
+
+	for _, v := range []string{
+		"NOMI_PREV_STDOUT=files",
+		"NOMI_PREV_EXIT_CODE=0",
+		"NOMI_STEP_1_STDOUT=files",
+		"NOMI_LIST_FILES_STDOUT=files",
+		"NOMI_LIST_FILES_EXIT_CODE=0",
+	} {
+		if !slices.Contains(executor.received[1], v) {
+			t.Errorf("second step env = %v, missing %s", executor.received[1], v)
+		}
+	}
+}
+
+func TestRegistryRunEnvBounded(t *testing.T) {
+	t.Parallel()
+
+	executor := &envExecutor{}
+	registry := NewRegistry()
+	registry.Register("env", executor)
+
+	large := strings.Repeat("é", maxEnvValue)
+	input := "```env\n" + large + "\n```\n```env\nsecond\n```\n```env\nthird\n```"
+	registry.Run(NewPlan(input, true))
+
+	if len(executor.received) != 3 {
+		t.Fatalf("expected 3 executions, got %d", len(executor.received))
+	}
+
+	second := executor.received[1]
+	for _, v := range second {
+		if len(v) > len("NOMI_STEP_1_STDOUT=")+maxEnvValue {
+			t.Errorf("second step env has %d bytes, want at most %d", len(v), maxEnvValue)
+		}
+		if !utf8.ValidString(v) {
+			t.Errorf("second step env has invalid UTF-8")
+		}
+	}
+
+	var prev []string
+	for _, v := range executor.received[2] {
+		if strings.HasPrefix(v, "NOMI_PREV_STDOUT=") {
+			prev = append(prev, v)
+		}
+	}
+	if !slices.Equal(prev, []string{"NOMI_PREV_STDOUT=second"}) {
+		t.Errorf("third step PREV = %v, want the second step only", prev)
 	}
 }
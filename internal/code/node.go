@@ -0,0 +1,17 @@
+package code
+
+import "os/exec"
+
+type NodeExecutor struct{}
+
+func (ne *NodeExecutor) Execute(req Request) ExecutionResult {
+	return runCommand(exec.Command("node", "-e", req.Code), req)
+}
+
+func (ne *NodeExecutor) Available() bool {
+	return binaryAvailable("node")
+}
+
+func initNodeExecutor(r *Registry) {
+	r.Register("node", &NodeExecutor{})
+}
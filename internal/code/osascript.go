@@ -1,35 +1,17 @@
 package code
 
-import (
-	"os/exec"
-	"strings"
-)
+import "os/exec"
 
 type OsascriptExecutor struct{}
 
-func (oe *OsascriptExecutor) Execute(code string) ExecutionResult {
-	cmd := exec.Command("osascript", "-e", code)
-	var stdout, stderr strings.Builder
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
-	exitCode := 0
-	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			exitCode = exitError.ExitCode()
-		} else {
-			exitCode = 1
-		}
-	}
+func (oe *OsascriptExecutor) Execute(req Request) ExecutionResult {
+	return runCommand(exec.Command("osascript", "-e", req.Code), req)
+}
 
-	return ExecutionResult{
-		Stdout:   stdout.String(),
-		Stderr:   stderr.String(),
-		ExitCode: exitCode,
-	}
+func (oe *OsascriptExecutor) Available() bool {
+	return binaryAvailable("osascript")
 }
 
-func initOsascriptExecutor() {
-	registerExecutor("osascript", &OsascriptExecutor{})
+func initOsascriptExecutor(r *Registry) {
+	r.Register("osascript", &OsascriptExecutor{})
 }
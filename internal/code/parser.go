@@ -5,28 +5,29 @@ import (
 	"strings"
 )
 
+const fence = "```"
+
+// ParseCodeBlocks returns every fenced code block of the input, in order.
+// The fence info string holds the language followed by optional
+// attributes, e.g. ```bash {name=setup skip}.
 func ParseCodeBlocks(input string) []Block {
-	var blocks []Block
+	blocks := make([]Block, 0)
 	scanner := bufio.NewScanner(strings.NewReader(input))
 	var currentBlock Block
 	inCodeBlock := false
-	var selectedLang string
 
 	for scanner.Scan() {
 		line := scanner.Text()
 
-		if strings.HasPrefix(line, "```") {
+		if strings.HasPrefix(line, fence) {
 			if inCodeBlock {
-				if currentBlock.Language == selectedLang {
-					blocks = append(blocks, currentBlock)
-				}
+				blocks = append(blocks, currentBlock)
 				currentBlock = Block{}
 				inCodeBlock = false
 			} else {
-				currentBlock.Language = strings.TrimSpace(strings.TrimPrefix(line, "```"))
-				if selectedLang == "" {
-					selectedLang = currentBlock.Language
-				}
+				currentBlock.Language, currentBlock.Attributes = parseFenceInfo(
+					strings.TrimPrefix(line, fence),
+				)
 				inCodeBlock = true
 			}
 		} else if inCodeBlock {
@@ -39,10 +40,35 @@ func ParseCodeBlocks(input string) []Block {
 	}
 
 	if inCodeBlock {
-		if currentBlock.Language == selectedLang {
-			blocks = append(blocks, currentBlock)
-		}
+		blocks = append(blocks, currentBlock)
 	}
 
 	return blocks
 }
+
+// parseFenceInfo splits a fence info string into the language and its
+// attributes. Attributes without a value are stored with an empty value.
+func parseFenceInfo(info string) (string, map[string]string) {
+	info = strings.TrimSpace(info)
+	language, rest, found := strings.Cut(info, "{")
+	language = strings.TrimSpace(language)
+	if !found {
+		return language, nil
+	}
+
+	rest, _, _ = strings.Cut(rest, "}")
+	fields := strings.FieldsFunc(rest, func(r rune) bool {
+		return r == ' ' || r == ',' || r == '\t'
+	})
+	if len(fields) == 0 {
+		return language, nil
+	}
+
+	attributes := make(map[string]string, len(fields))
+	for _, field := range fields {
+		key, value, _ := strings.Cut(field, "=")
+		attributes[key] = strings.Trim(value, `"'`)
+	}
+
+	return language, attributes
+}
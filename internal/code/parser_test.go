@@ -14,10 +14,11 @@ func TestParseCodeBlocks(t *testing.T) {
 		expected []Block
 	}{
 		{
-			name:  "Multiple languages, selects first language (python)",
+			name:  "Multiple languages, keeps every block (python first)",
 			input: "```python\nprint('Hello')\n```\n```bash\necho 'World'\n```",
 			expected: []Block{
 				{Language: "python", Code: "print('Hello')"},
+				{Language: "bash", Code: "echo 'World'"},
 			},
 		},
 		{
@@ -40,10 +41,37 @@ func TestParseCodeBlocks(t *testing.T) {
 			},
 		},
 		{
-			name:  "Multiple languages, selects first language (bash)",
+			name:  "Multiple languages, keeps every block (bash first)",
 			input: "```bash\necho 'World'\n```\n```python\nprint('Hello')\n```",
 			expected: []Block{
 				{Language: "bash", Code: "echo 'World'"},
+				{Language: "python", Code: "print('Hello')"},
+			},
+		},
+		{
+			name:  "Fence attributes",
+			input: "```bash {name=setup skip}\nmkdir -p /tmp/x\n```\n```python {name=\"report\", retries=2}\nprint(1)\n```",
+			expected: []Block{
+				{
+					Language:   "bash",
+					Code:       "mkdir -p /tmp/x",
+					Attributes: map[string]string{"name": "setup", "skip": ""},
+				},
+				{
+					Language: "python",
+					Code:     "print(1)",
+					Attributes: map[string]string{
+						"name":    "report",
+						"retries": "2",
+					},
+				},
+			},
+		},
+		{
+			name:  "Empty attributes",
+			input: "```bash {}\nls\n```",
+			expected: []Block{
+				{Language: "bash", Code: "ls"},
 			},
 		},
 	}
@@ -0,0 +1,17 @@
+package code
+
+import "os/exec"
+
+type PerlExecutor struct{}
+
+func (pe *PerlExecutor) Execute(req Request) ExecutionResult {
+	return runCommand(exec.Command("perl", "-e", req.Code), req)
+}
+
+func (pe *PerlExecutor) Available() bool {
+	return binaryAvailable("perl")
+}
+
+func initPerlExecutor(r *Registry) {
+	r.Register("perl", &PerlExecutor{})
+}
@@ -0,0 +1,35 @@
+package code
+
+import "os/exec"
+
+// PowershellExecutor prefers the cross-platform pwsh binary and falls back
+// to the Windows built-in powershell.
+type PowershellExecutor struct{}
+
+func (pe *PowershellExecutor) Execute(req Request) ExecutionResult {
+	return runCommand(
+		exec.Command(
+			pe.binary(),
+			"-NoProfile",
+			"-NonInteractive",
+			"-Command",
+			req.Code,
+		),
+		req,
+	)
+}
+
+func (pe *PowershellExecutor) Available() bool {
+	return binaryAvailable("pwsh") || binaryAvailable("powershell")
+}
+
+func (pe *PowershellExecutor) binary() string {
+	if binaryAvailable("pwsh") {
+		return "pwsh"
+	}
+	return "powershell"
+}
+
+func initPowershellExecutor(r *Registry) {
+	r.Register("powershell", &PowershellExecutor{})
+}
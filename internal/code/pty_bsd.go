@@ -0,0 +1,20 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package code
+
+import "golang.org/x/sys/unix"
+
+const (
+	ioctlGetTermios = unix.TIOCGETA
+	ioctlSetTermios = unix.TIOCSETA
+)
+
+// detectsReads tells whether waitingForInput inspects the processes. It
+// does not here, so only scripts printing a prompt are answered.
+const detectsReads = false
+
+// waitingForInput reports whether the script waits for input. The state
+// of processes is not inspected here: the idle timeout alone decides.
+func waitingForInput(int) bool {
+	return true
+}
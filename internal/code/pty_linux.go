@@ -0,0 +1,97 @@
+package code
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	ioctlGetTermios = unix.TCGETS
+	ioctlSetTermios = unix.TCSETS
+)
+
+// terminalWaits are the kernel functions where processes sleep while
+// reading a terminal, directly or by polling it.
+var terminalWaits = []string{
+	"n_tty_read",
+	"wait_woken",
+	"poll_schedule_timeout",
+	"do_select",
+	"core_sys_select",
+	"do_sys_poll",
+	"ep_poll",
+	"do_epoll_wait",
+}
+
+// detectsReads tells whether waitingForInput inspects the processes, so
+// that scripts reading without a prompt are answered too.
+const detectsReads = true
+
+// waitingForInput reports whether the processes of the session of the
+// script are blocked reading the terminal. A running process, or one
+// sleeping for another reason like a child or a timer, is not waiting
+// for an answer.
+func waitingForInput(sid int) bool {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return true
+	}
+
+	waiting := false
+	for _, e := range entries {
+		if _, err := strconv.Atoi(e.Name()); err != nil {
+			continue
+		}
+
+		stat, err := os.ReadFile(filepath.Join("/proc", e.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		// Fields follow the command name, which may hold spaces.
+		i := bytes.LastIndexByte(stat, ')')
+		if i < 0 {
+			continue
+		}
+		fields := strings.Fields(string(stat[i+1:]))
+		if len(fields) < 4 || fields[3] != strconv.Itoa(sid) {
+			continue
+		}
+
+		switch fields[0] {
+		case "R", "D":
+			return false
+		case "S":
+			if readingTerminal(e.Name()) {
+				waiting = true
+			}
+		}
+	}
+
+	return waiting
+}
+
+// readingTerminal reports whether the process sleeps where terminals are
+// read. It is assumed when the kernel hides it, like for processes of
+// other users such as sudo, but not for processes that already exited.
+func readingTerminal(pid string) bool {
+	wchan, err := os.ReadFile(filepath.Join("/proc", pid, "wchan"))
+	if err != nil {
+		return false
+	}
+	if len(wchan) == 0 || string(wchan) == "0" {
+		return true
+	}
+
+	for _, wait := range terminalWaits {
+		if strings.HasPrefix(string(wchan), wait) {
+			return true
+		}
+	}
+
+	return false
+}
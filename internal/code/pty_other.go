@@ -0,0 +1,11 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package code
+
+import "os/exec"
+
+// runInteractive falls back to a plain pipe where pseudo-terminals are not
+// supported, like on Windows: only req.Stdin is fed to the command.
+func runInteractive(cmd *exec.Cmd, req Request) ExecutionResult {
+	return runPiped(cmd, req)
+}
@@ -0,0 +1,220 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package code
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/creack/pty"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// promptIdleTimeout is how long a script must stay silent after printing
+	// an unterminated line before it is considered waiting for input.
+	promptIdleTimeout = 750 * time.Millisecond
+	// drainTimeout bounds the wait for output left in the terminal once
+	// the script exited, in case a background process holds it open.
+	drainTimeout   = 100 * time.Millisecond
+	ptyReadBufSize = 4096
+	// defaultPrompt is shown for scripts reading without a prompt.
+	defaultPrompt = "> "
+)
+
+// terminalEnv keeps programs attached to the terminal from waiting in a
+// pager, or filling the output with colors and progress bars.
+var terminalEnv = []string{
+	"TERM=dumb",
+	"PAGER=cat",
+	"GIT_PAGER=cat",
+	"NO_COLOR=1",
+}
+
+// runInteractive runs the command attached to a pseudo-terminal so that
+// programs prompting for input can be detected and answered through
+// req.Input. Stderr is kept separate but also watched for prompts, as
+// shells print them there. A prompt is the unterminated line, if any,
+// followed by silence while the script is blocked reading. Prompts read
+// while the script turned the echo off, like password prompts, are
+// reported as secret.
+func runInteractive(cmd *exec.Cmd, req Request) ExecutionResult {
+	ptmx, tty, err := pty.Open()
+	if err != nil {
+		return runPiped(cmd, req)
+	}
+	defer ptmx.Close()
+
+	// The terminal echoes the answers like the one of the user, which
+	// records them in the output, but does not translate LF to CRLF.
+	//nolint:errcheck
+	disableOutputProcessing(tty)
+
+	errChunks := make(chan []byte)
+	cmd.Stdin = tty
+	cmd.Stdout = tty
+	cmd.Stderr = chanWriter(errChunks)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, terminalEnv...)
+
+	if err := cmd.Start(); err != nil {
+		tty.Close()
+		return ExecutionResult{
+			Stderr:   "Failed to start script: " + err.Error(),
+			ExitCode: 1,
+		}
+	}
+	tty.Close()
+
+	// The reader stops once the output is no longer collected.
+	stop := make(chan struct{})
+	defer close(stop)
+
+	chunks := make(chan []byte)
+	go func() {
+		defer close(chunks)
+		buf := make([]byte, ptyReadBufSize)
+		for {
+			n, err := ptmx.Read(buf)
+			if n > 0 {
+				chunk := make([]byte, n)
+				copy(chunk, buf[:n])
+				select {
+				case chunks <- chunk:
+				case <-stop:
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	var stdout, stderr, pending strings.Builder
+	idle := time.NewTimer(promptIdleTimeout)
+	defer idle.Stop()
+
+	var waitErr error
+loop:
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				chunks = nil
+				continue
+			}
+			stdout.Write(chunk)
+			pending.Write(chunk)
+			idle.Reset(promptIdleTimeout)
+		case chunk := <-errChunks:
+			stderr.Write(chunk)
+			pending.Write(chunk)
+			idle.Reset(promptIdleTimeout)
+		case <-idle.C:
+			// The script is watched until it exits, it may prompt later.
+			idle.Reset(promptIdleTimeout)
+
+			// A line redrawn with carriage returns is a progress bar.
+			prompt := lastLine(pending.String())
+			if strings.Contains(prompt, "\r") {
+				continue
+			}
+			if prompt == "" && !detectsReads {
+				continue
+			}
+			if !waitingForInput(cmd.Process.Pid) {
+				continue
+			}
+
+			prompt = cleanOutput(prompt)
+			if strings.TrimSpace(prompt) == "" {
+				prompt = defaultPrompt
+			}
+			answer, err := req.Input(prompt, !echoEnabled(ptmx))
+			if err != nil {
+				//nolint:errcheck
+				cmd.Process.Kill()
+				continue
+			}
+
+			//nolint:errcheck
+			ptmx.WriteString(answer + "\n")
+			pending.Reset()
+		case waitErr = <-done:
+			break loop
+		}
+	}
+
+	drainOutput(chunks, &stdout)
+
+	return ExecutionResult{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		ExitCode: exitCode(waitErr, &stderr),
+	}
+}
+
+func disableOutputProcessing(tty *os.File) error {
+	termios, err := unix.IoctlGetTermios(int(tty.Fd()), ioctlGetTermios)
+	if err != nil {
+		return err
+	}
+
+	termios.Oflag &^= unix.OPOST
+	return unix.IoctlSetTermios(int(tty.Fd()), ioctlSetTermios, termios)
+}
+
+// echoEnabled reports whether the terminal echoes its input, which
+// programs turn off to read passwords. The echo is assumed on when the
+// state of the terminal cannot be read.
+func echoEnabled(f *os.File) bool {
+	termios, err := unix.IoctlGetTermios(int(f.Fd()), ioctlGetTermios)
+	return err != nil || termios.Lflag&unix.ECHO != 0
+}
+
+// chanWriter forwards copies of the written bytes to a channel.
+type chanWriter chan<- []byte
+
+func (w chanWriter) Write(p []byte) (int, error) {
+	chunk := make([]byte, len(p))
+	copy(chunk, p)
+	w <- chunk
+	return len(p), nil
+}
+
+func drainOutput(chunks <-chan []byte, stdout *strings.Builder) {
+	if chunks == nil {
+		return
+	}
+
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				return
+			}
+			stdout.Write(chunk)
+		case <-time.After(drainTimeout):
+			return
+		}
+	}
+}
+
+func lastLine(s string) string {
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
@@ -0,0 +1,181 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package code
+
+import (
+	"testing"
+)
+
+func TestRunPlanInteractive(t *testing.T) {
+	t.Parallel()
+
+	registry := NewDefaultRegistry()
+
+	var prompts []string
+	input := func(prompt string, secret bool) (string, error) {
+		if secret {
+			t.Errorf("Expected a prompt with echo, got a secret one")
+		}
+		prompts = append(prompts, prompt)
+		return "nomi", nil
+	}
+
+	results := registry.Run(Plan{
+		Steps: []Block{{
+			Language: "bash",
+			Code:     `read -p "Name: " name; echo "hello $name"`,
+		}},
+		Input: input,
+	})
+
+	if len(prompts) != 1 || prompts[0] != "Name: " {
+		t.Errorf("prompts = %q, want [\"Name: \"]", prompts)
+	}
+	if results[0].ExitCode != 0 || results[0].Stdout != "nomi\nhello nomi\n" {
+		t.Errorf(
+			"Run() = %q (exit %d, stderr %q)",
+			results[0].Stdout,
+			results[0].ExitCode,
+			results[0].Stderr,
+		)
+	}
+}
+
+func TestRunPlanInteractiveSecret(t *testing.T) {
+	t.Parallel()
+
+	registry := NewDefaultRegistry()
+
+	var secrets []bool
+	input := func(_ string, secret bool) (string, error) {
+		secrets = append(secrets, secret)
+		return "hunter2", nil
+	}
+
+	results := registry.Run(Plan{
+		Steps: []Block{{
+			Language: "bash",
+			Code:     `read -s -p "Password: " pw; echo; echo "${#pw}"`,
+		}},
+		Input: input,
+	})
+
+	if len(secrets) != 1 || !secrets[0] {
+		t.Errorf("secrets = %v, want [true]", secrets)
+	}
+	if results[0].Stdout != "\n7\n" {
+		t.Errorf(
+			"Run() = %q (exit %d, stderr %q), want %q",
+			results[0].Stdout,
+			results[0].ExitCode,
+			results[0].Stderr,
+			"\n7\n",
+		)
+	}
+}
+
+func TestRunPlanInteractiveTerminal(t *testing.T) {
+	t.Parallel()
+
+	registry := NewDefaultRegistry()
+	input := func(prompt string, _ bool) (string, error) {
+		t.Errorf("Expected no prompt, got %q", prompt)
+		return "", nil
+	}
+
+	// The unterminated line is followed by silence, but the script is
+	// sleeping rather than reading.
+	results := registry.Run(Plan{
+		Steps: []Block{{
+			Language: "bash",
+			Code: `printf 'working'; sleep 1.5; echo ' done'
+echo "$TERM $PAGER $GIT_PAGER $NO_COLOR"`,
+		}},
+		Input: input,
+	})
+
+	want := "working done\ndumb cat cat 1\n"
+	if results[0].Stdout != want {
+		t.Errorf(
+			"Run() = %q (exit %d, stderr %q), want %q",
+			results[0].Stdout,
+			results[0].ExitCode,
+			results[0].Stderr,
+			want,
+		)
+	}
+}
+
+func TestRunPlanStdin(t *testing.T) {
+	t.Parallel()
+
+	registry := NewDefaultRegistry()
+	results := registry.Run(Plan{
+		Steps: []Block{{Language: "bash", Code: `read name; echo "hello $name"`}},
+		Stdin: "stdin\n",
+	})
+
+	if results[0].Stdout != "hello stdin\n" {
+		t.Errorf("Run() = %q, want %q", results[0].Stdout, "hello stdin\n")
+	}
+}
+
+func TestRunPlanInteractiveWithoutPrompt(t *testing.T) {
+	t.Parallel()
+
+	if !detectsReads {
+		t.Skip("Reads without a prompt are not detected on this system")
+	}
+
+	registry := NewDefaultRegistry()
+
+	var prompts []string
+	input := func(prompt string, _ bool) (string, error) {
+		prompts = append(prompts, prompt)
+		return "nomi", nil
+	}
+
+	results := registry.Run(Plan{
+		Steps: []Block{{Language: "bash", Code: `read x; echo "got $x"`}},
+		Input: input,
+	})
+
+	if len(prompts) != 1 || prompts[0] != defaultPrompt {
+		t.Errorf("prompts = %q, want [%q]", prompts, defaultPrompt)
+	}
+	if results[0].Stdout != "nomi\ngot nomi\n" {
+		t.Errorf(
+			"Run() = %q (exit %d, stderr %q)",
+			results[0].Stdout,
+			results[0].ExitCode,
+			results[0].Stderr,
+		)
+	}
+}
+
+func TestRunPlanInteractiveStdin(t *testing.T) {
+	t.Parallel()
+
+	registry := NewDefaultRegistry()
+	input := func(prompt string, _ bool) (string, error) {
+		t.Errorf("Expected no prompt, got %q", prompt)
+		return "", nil
+	}
+
+	// sort reads its input to the end before printing anything.
+	results := registry.Run(Plan{
+		Steps: []Block{{Language: "bash", Code: "sort"}},
+		Stdin: "b\na\n",
+		Input: input,
+	})
+
+	if results[0].Stdout != "a\nb\n" {
+		t.Errorf(
+			"Run() = %q (exit %d, stderr %q), want %q",
+			results[0].Stdout,
+			results[0].ExitCode,
+			results[0].Stderr,
+			"a\nb\n",
+		)
+	}
+}
@@ -1,35 +1,24 @@
 package code
 
-import (
-	"os/exec"
-	"strings"
-)
+import "os/exec"
 
 type PythonExecutor struct{}
 
-func (pe *PythonExecutor) Execute(code string) ExecutionResult {
-	cmd := exec.Command("python3", "-c", code)
-	var stdout, stderr strings.Builder
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
-	exitCode := 0
-	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
-			exitCode = exitError.ExitCode()
-		} else {
-			exitCode = 1
-		}
+func (pe *PythonExecutor) Execute(req Request) ExecutionResult {
+	if req.StateDir != "" {
+		return runCommand(
+			exec.Command("python3", "-c", pythonStatePrelude, req.Code),
+			req,
+		)
 	}
 
-	return ExecutionResult{
-		Stdout:   stdout.String(),
-		Stderr:   stderr.String(),
-		ExitCode: exitCode,
-	}
+	return runCommand(exec.Command("python3", "-c", req.Code), req)
+}
+
+func (pe *PythonExecutor) Available() bool {
+	return binaryAvailable("python3")
 }
 
-func initPythonExecutor() {
-	registerExecutor("python", &PythonExecutor{})
+func initPythonExecutor(r *Registry) {
+	r.Register("python", &PythonExecutor{})
 }
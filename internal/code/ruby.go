@@ -0,0 +1,17 @@
+package code
+
+import "os/exec"
+
+type RubyExecutor struct{}
+
+func (re *RubyExecutor) Execute(req Request) ExecutionResult {
+	return runCommand(exec.Command("ruby", "-e", req.Code), req)
+}
+
+func (re *RubyExecutor) Available() bool {
+	return binaryAvailable("ruby")
+}
+
+func initRubyExecutor(r *Registry) {
+	r.Register("ruby", &RubyExecutor{})
+}
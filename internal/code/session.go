@@ -0,0 +1,153 @@
+package code
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	stateDirEnv  = "NOMI_STATE_DIR"
+	stateCwdFile = "cwd"
+	stateEnvFile = "env"
+)
+
+// volatileEnv lists variables managed by the shell itself that must not
+// be carried from one execution to the next.
+var volatileEnv = map[string]struct{}{
+	"_":      {},
+	"PWD":    {},
+	"OLDPWD": {},
+	"SHLVL":  {},
+}
+
+// Session is the shell state shared by consecutive executions of a
+// conversation: the working directory and environment left by a script
+// are captured when it exits and restored for the next one.
+type Session struct {
+	mu  sync.Mutex
+	dir string
+	env map[string]string
+}
+
+// NewSession starts a session from the current process state.
+func NewSession() (*Session, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("error getting working directory: %w", err)
+	}
+
+	return &Session{
+		dir: dir,
+		env: parseEnv(os.Environ()),
+	}, nil
+}
+
+// Cwd returns the session working directory.
+func (s *Session) Cwd() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.dir
+}
+
+// Environ returns the session environment as sorted KEY=VALUE pairs.
+func (s *Session) Environ() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	env := make([]string, 0, len(s.env))
+	for k, v := range s.env {
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
+
+	return env
+}
+
+// capture updates the session from the state files written by a script.
+// Missing files mean the executor does not support state capture.
+func (s *Session) capture(stateDir string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cwd, err := os.ReadFile(filepath.Join(stateDir, stateCwdFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error reading captured directory: %w", err)
+	}
+	if dir := strings.TrimSpace(string(cwd)); dir != "" {
+		s.dir = dir
+	}
+
+	data, err := os.ReadFile(filepath.Join(stateDir, stateEnvFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading captured environment: %w", err)
+	}
+
+	sep := []byte{'\n'}
+	if bytes.IndexByte(data, 0) >= 0 {
+		sep = []byte{0}
+	}
+
+	var lines []string
+	for _, line := range bytes.Split(data, sep) {
+		if len(line) > 0 {
+			lines = append(lines, string(line))
+		}
+	}
+	if len(lines) > 0 {
+		s.env = parseEnv(lines)
+	}
+
+	return nil
+}
+
+func parseEnv(environ []string) map[string]string {
+	env := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || k == "" {
+			continue
+		}
+		if _, ok := volatileEnv[k]; ok || strings.HasPrefix(k, envPrefix) {
+			continue
+		}
+		env[k] = v
+	}
+
+	return env
+}
+
+// shellStateTrap returns code saving the state of a POSIX shell on exit.
+func shellStateTrap() string {
+	return `__nomi_save_state() {
+	pwd > "$` + stateDirEnv + `/` + stateCwdFile + `"
+	env -0 > "$` + stateDirEnv + `/` + stateEnvFile + `" 2>/dev/null ||
+		env > "$` + stateDirEnv + `/` + stateEnvFile + `"
+}
+trap __nomi_save_state EXIT
+`
+}
+
+// pythonStatePrelude runs the code given as first argument and saves
+// the interpreter state on exit.
+const pythonStatePrelude = `import atexit, os, sys
+def __nomi_save_state():
+    d = os.environ.get("` + stateDirEnv + `")
+    with open(os.path.join(d, "` + stateCwdFile + `"), "w") as f:
+        f.write(os.getcwd())
+    with open(os.path.join(d, "` + stateEnvFile + `"), "w") as f:
+        f.write("\0".join(k + "=" + v for k, v in os.environ.items()))
+atexit.register(__nomi_save_state)
+__nomi_code = sys.argv[1]
+sys.argv = ["-c"]
+exec(compile(__nomi_code, "<string>", "exec"), {"__name__": "__main__"})
+`
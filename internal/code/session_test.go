@@ -0,0 +1,77 @@
+package code
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSessionPersistsState(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		language string
+		setup    string
+	}{
+		{
+			name:     "Bash",
+			language: "bash",
+			setup:    "cd \"$TARGET\" && export GREETING=hello",
+		},
+		{
+			name:     "Python",
+			language: "python",
+			setup:    "import os\nos.chdir(os.environ['TARGET'])\nos.environ['GREETING'] = 'hello'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			registry := NewDefaultRegistry()
+			executor, _, _ := registry.lookup(tt.language)
+			if !executor.(availabilityChecker).Available() {
+				t.Skipf("%s is not installed", tt.language)
+			}
+
+			target, err := filepath.EvalSymlinks(t.TempDir())
+			if err != nil {
+				t.Fatalf("failed to resolve temp dir: %v", err)
+			}
+
+			session, err := NewSession()
+			if err != nil {
+				t.Fatalf("NewSession() error = %v", err)
+			}
+			session.env["TARGET"] = target
+
+			setup := registry.Run(Plan{
+				Steps:   []Block{{Language: tt.language, Code: tt.setup}},
+				Session: session,
+			})
+			if setup[0].ExitCode != 0 {
+				t.Fatalf("setup failed: %s", setup[0].Stderr)
+			}
+
+			if session.Cwd() != target {
+				t.Errorf("Cwd() = %s, want %s", session.Cwd(), target)
+			}
+
+			check := registry.Run(Plan{
+				Steps: []Block{{
+					Language: "bash",
+					Code:     "echo \"$PWD $GREETING\"",
+				}},
+				Session: session,
+			})
+			if expected := target + " hello\n"; check[0].Stdout != expected {
+				t.Errorf(
+					"state not restored: got %q, want %q",
+					check[0].Stdout,
+					expected,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package code
+
+import "os/exec"
+
+type ShExecutor struct{}
+
+func (se *ShExecutor) Execute(req Request) ExecutionResult {
+	code := req.Code
+	if req.StateDir != "" {
+		code = shellStateTrap() + code
+	}
+
+	return runCommand(exec.Command("sh", "-c", code), req)
+}
+
+func (se *ShExecutor) Available() bool {
+	return binaryAvailable("sh")
+}
+
+func initShExecutor(r *Registry) {
+	r.Register("sh", &ShExecutor{})
+}
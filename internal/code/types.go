@@ -1,10 +1,25 @@
 package code
 
+import "time"
+
 type Block struct {
 	ID          string
 	Language    string
 	Code        string
 	Description string
+	// Attributes holds the fence attributes, e.g. {name=setup skip}.
+	Attributes map[string]string
+}
+
+// Name returns the name given to the block with the name attribute.
+func (b Block) Name() string {
+	return b.Attributes["name"]
+}
+
+// Skipped reports whether the block is marked with the skip attribute.
+func (b Block) Skipped() bool {
+	_, ok := b.Attributes["skip"]
+	return ok
 }
 
 type ExecutionResult struct {
@@ -12,8 +27,30 @@ type ExecutionResult struct {
 	Stderr   string
 	ExitCode int
 	Block    Block
+	Duration time.Duration
+}
+
+// Request is the input of an executor.
+type Request struct {
+	Code string
+	// Env is the process environment, the current one is inherited when nil.
+	Env []string
+	// Dir is the working directory, the current one is used when empty.
+	Dir string
+	// StateDir, when set, is where executors supporting it save the
+	// working directory and environment left by the script.
+	StateDir string
+	// Stdin is written to the standard input of the script.
+	Stdin string
+	// Input, when set, is called with the prompt when the script waits
+	// for input, and its answer is sent to the script.
+	Input InputFunc
 }
 
+// InputFunc answers a prompt printed by a running script. Secret is set
+// when the script does not echo the answer, like for a password.
+type InputFunc func(prompt string, secret bool) (string, error)
+
 type Executor interface {
-	Execute(code string) ExecutionResult
+	Execute(req Request) ExecutionResult
 }
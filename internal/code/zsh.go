@@ -0,0 +1,22 @@
+package code
+
+import "os/exec"
+
+type ZshExecutor struct{}
+
+func (ze *ZshExecutor) Execute(req Request) ExecutionResult {
+	code := req.Code
+	if req.StateDir != "" {
+		code = shellStateTrap() + code
+	}
+
+	return runCommand(exec.Command("zsh", "-c", code), req)
+}
+
+func (ze *ZshExecutor) Available() bool {
+	return binaryAvailable("zsh")
+}
+
+func initZshExecutor(r *Registry) {
+	r.Register("zsh", &ZshExecutor{})
+}
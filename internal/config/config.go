@@ -0,0 +1,439 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/nullswan/llama-hackaton/internal/code"
+	"github.com/nullswan/llama-hackaton/internal/paths"
+)
+
+const (
+	fileName        = "config.toml"
+	projectFileName = ".nomi.toml"
+	// legacyExecutorsFileName defined the custom executors before they
+	// moved to the configuration file.
+	legacyExecutorsFileName = "executors.json"
+)
+
+// ErrUserOnlyKey is returned when a project file sets a key that only the
+// user file can set.
+var ErrUserOnlyKey = errors.New("key can only be set in the user config")
+
+// userOnlySections are the tables a project file cannot set: a cloned
+// repository must not choose the server conversations are sent to, the
+// commands running the code, or the safety settings.
+var userOnlySections = []string{"provider", "executors", "safety"}
+
+// Config is the nomi configuration. It is built from the defaults, the
+// user file, the project file, the environment and the command line, each
+// layer overriding the previous ones.
+type Config struct {
+	Provider  ProviderConfig  `toml:"provider"`
+	Sampling  SamplingConfig  `toml:"sampling"`
+	Retry     RetryConfig     `toml:"retry"`
+	Executors ExecutorsConfig `toml:"executors"`
+	Safety    SafetyConfig    `toml:"safety"`
+	UI        UIConfig        `toml:"ui"`
+}
+
+type ProviderConfig struct {
+	// URL is the address of the ollama server.
+	URL string `toml:"url"`
+	// Model, when set, is used for every completion.
+	Model string `toml:"model"`
+	// FastModel answers questions and simple requests.
+	FastModel string `toml:"fast_model"`
+	// StrongModel generates code and handles retries.
+	StrongModel string `toml:"strong_model"`
+}
+
+// SamplingConfig tunes the generation of completions. Unset values keep
+// the defaults of the server.
+type SamplingConfig struct {
+	Temperature *float64 `toml:"temperature"`
+	TopP        *float64 `toml:"top_p"`
+	// NumCtx is the size of the context window in tokens.
+	NumCtx *int `toml:"num_ctx"`
+	// Seed makes completions reproducible for identical conversations.
+	Seed *int `toml:"seed"`
+	// KeepAlive is how long the models stay loaded after a request, as a
+	// duration such as "10m", or "-1" to keep them loaded.
+	KeepAlive string `toml:"keep_alive"`
+
+	// Code is the profile of completions expected to generate code.
+	Code SamplingProfile `toml:"code"`
+	// Chat is the profile of questions and answers.
+	Chat SamplingProfile `toml:"chat"`
+}
+
+// SamplingProfile overrides the sampling of a kind of completion.
+type SamplingProfile struct {
+	Temperature *float64 `toml:"temperature"`
+	TopP        *float64 `toml:"top_p"`
+}
+
+type RetryConfig struct {
+	// MaxAttempts is the number of failed attempts after which a request
+	// is abandoned.
+	MaxAttempts int `toml:"max_attempts"`
+	// EscalateAfter switches to EscalateModel after that many failed
+	// attempts. Zero disables the escalation.
+	EscalateAfter int    `toml:"escalate_after"`
+	EscalateModel string `toml:"escalate_model"`
+	// HintAfter asks the model to try a different approach after that
+	// many failed attempts. Zero disables the hint.
+	HintAfter int `toml:"hint_after"`
+}
+
+type ExecutorsConfig struct {
+	// ContinueOnError keeps running the remaining blocks after one fails.
+	ContinueOnError bool `toml:"continue_on_error"`
+	// Custom defines additional executors.
+	Custom []code.ExecutorSpec `toml:"custom"`
+}
+
+type SafetyConfig struct {
+	// Redact lists regular expressions of secrets to redact before
+	// sending the conversation to the model.
+	Redact []string `toml:"redact"`
+	// Verify asks the model to check the goal was achieved after a
+	// successful execution.
+	Verify bool `toml:"verify"`
+	// ProjectPrompts loads the prompts of the .nomi/prompts directory of
+	// the project, after the ones of the user. They are ignored otherwise,
+	// as they decide what code is generated.
+	ProjectPrompts bool `toml:"project_prompts"`
+}
+
+type UIConfig struct {
+	// Summarize asks the model to answer in prose from the output of
+	// successful scripts.
+	Summarize bool `toml:"summarize"`
+	// Debug enables debug logs.
+	Debug bool `toml:"debug"`
+	// DevMode prints the internal steps of the interpreter.
+	DevMode bool `toml:"dev_mode"`
+	// HistorySize is the number of input lines saved across sessions.
+	// Zero keeps the history of the session only.
+	HistorySize int `toml:"history_size"`
+	// EditMode is the key bindings of the input line, emacs or vi.
+	EditMode string `toml:"edit_mode"`
+}
+
+// Default returns the configuration used when nothing is set.
+func Default() Config {
+	return Config{
+		Provider: ProviderConfig{
+			URL: "http://localhost:11434",
+		},
+		Sampling: SamplingConfig{
+			Code: SamplingProfile{
+				Temperature: ptr(0.2),
+			},
+			Chat: SamplingProfile{
+				Temperature: ptr(0.7),
+			},
+		},
+		Retry: RetryConfig{
+			MaxAttempts: 3,
+			HintAfter:   2,
+		},
+		UI: UIConfig{
+			Summarize:   true,
+			DevMode:     true,
+			HistorySize: 1000,
+			EditMode:    "emacs",
+		},
+	}
+}
+
+// UserPath returns the location of the user configuration file.
+func UserPath() (string, error) {
+	dir, err := paths.ConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("error locating config directory: %w", err)
+	}
+
+	return filepath.Join(dir, fileName), nil
+}
+
+// ProjectPath returns the project configuration file of dir or of its
+// nearest parent, or an empty string if there is none.
+func ProjectPath(dir string) string {
+	for {
+		path := filepath.Join(dir, projectFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// UserOnly reports whether a key can only be set in the user file.
+func UserOnly(key string) bool {
+	section, _, _ := strings.Cut(key, ".")
+	return slices.Contains(userOnlySections, section)
+}
+
+// Load builds the configuration from the defaults, the user and project
+// files and the environment. It returns the files that were read.
+func Load() (Config, []string, error) {
+	userPath, err := UserPath()
+	if err != nil {
+		return Config{}, nil, err
+	}
+
+	if err := migrateExecutors(userPath); err != nil {
+		return Config{}, nil, err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return Config{}, nil, fmt.Errorf("error getting working directory: %w", err)
+	}
+
+	return load(userPath, ProjectPath(cwd), os.Environ())
+}
+
+func load(
+	userPath, projectPath string,
+	environ []string,
+) (Config, []string, error) {
+	cfg := Default()
+	var sources []string
+
+	for _, path := range []string{userPath, projectPath} {
+		if path == "" {
+			continue
+		}
+
+		found, err := decodeFile(path, &cfg, path == projectPath)
+		if err != nil {
+			return Config{}, nil, err
+		}
+		if found {
+			sources = append(sources, path)
+		}
+	}
+
+	if err := applyEnv(&cfg, environ); err != nil {
+		return Config{}, nil, err
+	}
+
+	return cfg, sources, nil
+}
+
+// decodeFile overrides cfg with the keys set in the file. It returns
+// false when the file does not exist. Project files cannot set the keys
+// reserved to the user file.
+func decodeFile(path string, cfg *Config, project bool) (bool, error) {
+	md, err := toml.DecodeFile(path, cfg)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error reading config %s: %w", path, err)
+	}
+
+	if project {
+		for _, key := range md.Keys() {
+			if UserOnly(key.String()) {
+				return false, fmt.Errorf(
+					"error reading config %s: %w: %s",
+					path,
+					ErrUserOnlyKey,
+					key,
+				)
+			}
+		}
+	}
+
+	return true, nil
+}
+
+// migrateExecutors moves the executors of the legacy executors.json file,
+// next to the user file, to its executors.custom key. The legacy file is
+// removed once moved.
+func migrateExecutors(userPath string) error {
+	legacyPath := filepath.Join(filepath.Dir(userPath), legacyExecutorsFileName)
+	data, err := os.ReadFile(legacyPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading executors file: %w", err)
+	}
+
+	var specs []code.ExecutorSpec
+	if err := json.Unmarshal(data, &specs); err != nil {
+		return fmt.Errorf("error parsing executors file: %w", err)
+	}
+
+	doc := make(map[string]any)
+	var user Config
+	for _, v := range []any{&doc, &user} {
+		if _, err := toml.DecodeFile(userPath, v); err != nil &&
+			!errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("error reading config %s: %w", userPath, err)
+		}
+	}
+
+	executors, ok := doc["executors"].(map[string]any)
+	if !ok {
+		executors = make(map[string]any)
+		doc["executors"] = executors
+	}
+	executors["custom"] = append(user.Executors.Custom, specs...)
+
+	if err := writeFile(userPath, doc); err != nil {
+		return err
+	}
+	if err := os.Remove(legacyPath); err != nil {
+		return fmt.Errorf("error removing executors file: %w", err)
+	}
+
+	return nil
+}
+
+// envAliases maps the historical environment variables to their keys.
+var envAliases = map[string]string{
+	"OLLAMA_URL": "provider.url",
+	"DEBUG":      "ui.debug",
+}
+
+// EnvName returns the environment variable overriding a key, for
+// example NOMI_RETRY_MAX_ATTEMPTS for retry.max_attempts.
+func EnvName(key string) string {
+	return "NOMI_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+func applyEnv(cfg *Config, environ []string) error {
+	env := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+
+	for name, key := range envAliases {
+		v, ok := env[name]
+		if !ok || v == "" {
+			continue
+		}
+		if key == "ui.debug" {
+			v = "true"
+		}
+		if err := Set(cfg, key, v); err != nil {
+			return fmt.Errorf("error applying %s: %w", name, err)
+		}
+	}
+
+	for _, key := range Keys() {
+		v, ok := env[EnvName(key)]
+		if !ok {
+			continue
+		}
+		values := []string{v}
+		if value, _ := lookup(cfg, key); value.Kind() == reflect.Slice {
+			values = splitList(v)
+		}
+		if err := Set(cfg, key, values...); err != nil {
+			return fmt.Errorf("error applying %s: %w", EnvName(key), err)
+		}
+	}
+
+	return nil
+}
+
+// splitList splits the comma separated values of list variables.
+func splitList(v string) []string {
+	if v == "" {
+		return nil
+	}
+
+	return strings.Split(v, ",")
+}
+
+// SetInFile sets a key in a configuration file, keeping the other keys
+// of the file as they are. Unsetting an optional key removes it.
+func SetInFile(path, key string, values ...string) error {
+	var scratch Config
+	if err := Set(&scratch, key, values...); err != nil {
+		return err
+	}
+	value, _ := lookup(&scratch, key)
+
+	doc := make(map[string]any)
+	if _, err := toml.DecodeFile(path, &doc); err != nil &&
+		!errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error reading config %s: %w", path, err)
+	}
+
+	names := strings.Split(key, ".")
+	table := doc
+	for _, name := range names[:len(names)-1] {
+		child, ok := table[name].(map[string]any)
+		if !ok {
+			child = make(map[string]any)
+			table[name] = child
+		}
+		table = child
+	}
+
+	name := names[len(names)-1]
+	switch {
+	case value.Kind() == reflect.Pointer && value.IsNil():
+		delete(table, name)
+	case value.Kind() == reflect.Pointer:
+		table[name] = value.Elem().Interface()
+	default:
+		table[name] = value.Interface()
+	}
+
+	return writeFile(path, doc)
+}
+
+func writeFile(path string, doc map[string]any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("error creating config directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("error opening config %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if err := toml.NewEncoder(file).Encode(doc); err != nil {
+		return fmt.Errorf("error writing config %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Encode writes the configuration as TOML.
+func Encode(cfg Config) (string, error) {
+	var sb strings.Builder
+	if err := toml.NewEncoder(&sb).Encode(cfg); err != nil {
+		return "", fmt.Errorf("error encoding config: %w", err)
+	}
+
+	return sb.String(), nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,335 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cfg, sources, err := load(filepath.Join(dir, fileName), "", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(sources) != 0 {
+		t.Errorf("Expected no sources, got %v", sources)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("Expected default config, got %+v", cfg)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	userPath := filepath.Join(dir, "user", fileName)
+	projectPath := filepath.Join(dir, "project", projectFileName)
+	writeConfig(t, userPath, `
+[provider]
+model = "user-model"
+url = "http://user:11434"
+
+[retry]
+max_attempts = 5
+
+[[executors.custom]]
+language = "lua"
+command = ["lua", "{file}"]
+`)
+	writeConfig(t, projectPath, `
+[sampling]
+seed = 42
+
+[ui]
+summarize = false
+`)
+
+	cfg, sources, err := load(userPath, projectPath, []string{
+		"OLLAMA_URL=http://env:11434",
+		"NOMI_RETRY_MAX_ATTEMPTS=7",
+		"NOMI_SAFETY_REDACT=a,b",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(sources, []string{userPath, projectPath}) {
+		t.Errorf("Unexpected sources %v", sources)
+	}
+	if cfg.Provider.Model != "user-model" {
+		t.Errorf("Expected user model, got %q", cfg.Provider.Model)
+	}
+	if cfg.Sampling.Seed == nil || *cfg.Sampling.Seed != 42 {
+		t.Errorf("Expected project seed, got %v", cfg.Sampling.Seed)
+	}
+	if cfg.Provider.URL != "http://env:11434" {
+		t.Errorf("Expected env url, got %q", cfg.Provider.URL)
+	}
+	if cfg.Retry.MaxAttempts != 7 {
+		t.Errorf("Expected 7 attempts, got %d", cfg.Retry.MaxAttempts)
+	}
+	if cfg.Retry.HintAfter != Default().Retry.HintAfter {
+		t.Errorf("Expected default hint, got %d", cfg.Retry.HintAfter)
+	}
+	if cfg.UI.Summarize {
+		t.Errorf("Expected summarize to be disabled by the project")
+	}
+	if !reflect.DeepEqual(cfg.Safety.Redact, []string{"a", "b"}) {
+		t.Errorf("Unexpected redact patterns %v", cfg.Safety.Redact)
+	}
+	if len(cfg.Executors.Custom) != 1 ||
+		cfg.Executors.Custom[0].Language != "lua" {
+		t.Errorf("Unexpected custom executors %+v", cfg.Executors.Custom)
+	}
+}
+
+func TestLoadProjectUserOnly(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		project string
+	}{
+		{
+			name:    "Provider",
+			project: "[provider]\nurl = \"http://attacker:11434\"\n",
+		},
+		{
+			name:    "Executors",
+			project: "[[executors.custom]]\nlanguage = \"bash\"\ncommand = [\"evil\"]\n",
+		},
+		{
+			name:    "Safety",
+			project: "[safety]\nredact = []\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			projectPath := filepath.Join(t.TempDir(), projectFileName)
+			writeConfig(t, projectPath, tt.project)
+
+			_, _, err := load("", projectPath, nil)
+			if !errors.Is(err, ErrUserOnlyKey) {
+				t.Errorf("Expected ErrUserOnlyKey, got %v", err)
+			}
+		})
+	}
+}
+
+func TestMigrateExecutors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	userPath := filepath.Join(dir, fileName)
+	legacyPath := filepath.Join(dir, legacyExecutorsFileName)
+	writeConfig(t, userPath, `
+[provider]
+model = "user-model"
+
+[[executors.custom]]
+language = "lua"
+command = ["lua", "{file}"]
+`)
+	writeConfig(t, legacyPath, `[
+	{"language": "deno", "command": ["deno", "run"], "extension": ".ts"}
+]`)
+
+	if err := migrateExecutors(userPath); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := os.Stat(legacyPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected the legacy file to be removed, got %v", err)
+	}
+
+	cfg, _, err := load(userPath, "", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.Provider.Model != "user-model" {
+		t.Errorf("Expected the user keys to be kept, got %q", cfg.Provider.Model)
+	}
+	languages := make([]string, len(cfg.Executors.Custom))
+	for i, spec := range cfg.Executors.Custom {
+		languages[i] = spec.Language
+	}
+	if !reflect.DeepEqual(languages, []string{"lua", "deno"}) {
+		t.Errorf("Expected lua and deno executors, got %+v", cfg.Executors.Custom)
+	}
+
+	// Without the legacy file, the migration does nothing.
+	if err := migrateExecutors(userPath); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestSetGet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		key      string
+		values   []string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "String",
+			key:      "provider.model",
+			values:   []string{"llama3.2"},
+			expected: "llama3.2",
+		},
+		{
+			name:     "Int",
+			key:      "retry.max_attempts",
+			values:   []string{"4"},
+			expected: "4",
+		},
+		{
+			name:     "Bool",
+			key:      "safety.verify",
+			values:   []string{"true"},
+			expected: "true",
+		},
+		{
+			name:     "List",
+			key:      "safety.redact",
+			values:   []string{"a", "b"},
+			expected: "a,b",
+		},
+		{
+			name:     "Optional float",
+			key:      "sampling.code.temperature",
+			values:   []string{"0"},
+			expected: "0",
+		},
+		{
+			name:     "Unset optional",
+			key:      "sampling.chat.temperature",
+			values:   []string{""},
+			expected: "",
+		},
+		{
+			name:     "Optional int",
+			key:      "sampling.seed",
+			values:   []string{"42"},
+			expected: "42",
+		},
+		{
+			name:    "Section",
+			key:     "sampling.code",
+			values:  []string{"x"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid int",
+			key:     "retry.max_attempts",
+			values:  []string{"many"},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown key",
+			key:     "provider.unknown",
+			values:  []string{"x"},
+			wantErr: true,
+		},
+		{
+			name:    "Unsupported type",
+			key:     "executors.custom",
+			values:  []string{"x"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := Default()
+			err := Set(&cfg, tt.key, tt.values...)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			got, err := Get(cfg, tt.key)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSetInFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "nomi", fileName)
+	writeConfig(t, path, `
+[ui]
+summarize = false
+`)
+
+	if err := SetInFile(path, "provider.model", "llama3.2:latest"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := SetInFile(path, "sampling.code.temperature", "0.1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	cfg := Default()
+	if _, err := decodeFile(path, &cfg, false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.Provider.Model != "llama3.2:latest" {
+		t.Errorf("Expected model to be set, got %q", cfg.Provider.Model)
+	}
+	if cfg.UI.Summarize {
+		t.Errorf("Expected existing keys to be kept")
+	}
+	if got := cfg.Sampling.Code.Temperature; got == nil || *got != 0.1 {
+		t.Errorf("Expected nested key to be set, got %v", got)
+	}
+}
+
+func TestProjectPath(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, projectFileName)
+	writeConfig(t, path, "")
+	nested := filepath.Join(dir, "a", "b")
+	if err := os.MkdirAll(nested, 0o700); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	if got := ProjectPath(nested); got != path {
+		t.Errorf("Expected %q, got %q", path, got)
+	}
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnknownKey      = errors.New("unknown config key")
+	ErrUnsupportedType = errors.New("key cannot be set from a string")
+)
+
+// Keys returns the keys that can be read and set, as dotted paths such
+// as retry.max_attempts or sampling.code.temperature.
+func Keys() []string {
+	return appendKeys(nil, "", reflect.TypeOf(Config{}))
+}
+
+func appendKeys(keys []string, prefix string, t reflect.Type) []string {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		key := prefix + tomlName(field)
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			keys = appendKeys(keys, key+".", field.Type)
+		case settable(field.Type):
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// Get returns the value of a key, lists being comma separated and unset
+// optional values empty.
+func Get(cfg Config, key string) (string, error) {
+	value, err := lookup(&cfg, key)
+	if err != nil {
+		return "", err
+	}
+
+	switch value.Kind() {
+	case reflect.Slice:
+		items := make([]string, value.Len())
+		for i := range items {
+			items[i] = value.Index(i).String()
+		}
+		return strings.Join(items, ","), nil
+	case reflect.Pointer:
+		if value.IsNil() {
+			return "", nil
+		}
+		value = value.Elem()
+	}
+
+	return fmt.Sprint(value.Interface()), nil
+}
+
+// Set parses and assigns a key. Lists take every value, other keys
+// exactly one. An empty value unsets optional keys.
+func Set(cfg *Config, key string, values ...string) error {
+	value, err := lookup(cfg, key)
+	if err != nil {
+		return err
+	}
+
+	if value.Kind() == reflect.Slice {
+		items := make([]string, 0, len(values))
+		for _, v := range values {
+			if v != "" {
+				items = append(items, v)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+		return nil
+	}
+
+	if len(values) != 1 {
+		return fmt.Errorf("%s expects a single value, got %d", key, len(values))
+	}
+	v := values[0]
+
+	if value.Kind() == reflect.Pointer {
+		if v == "" {
+			value.SetZero()
+			return nil
+		}
+		value.Set(reflect.New(value.Type().Elem()))
+		value = value.Elem()
+	}
+
+	return setScalar(value, key, v)
+}
+
+func setScalar(value reflect.Value, key, v string) error {
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(v)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid boolean for %s: %w", key, err)
+		}
+		value.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid integer for %s: %w", key, err)
+		}
+		value.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number for %s: %w", key, err)
+		}
+		value.SetFloat(f)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, key)
+	}
+
+	return nil
+}
+
+func lookup(cfg *Config, key string) (reflect.Value, error) {
+	value := reflect.ValueOf(cfg).Elem()
+	for _, name := range strings.Split(key, ".") {
+		if value.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("%w: %s", ErrUnknownKey, key)
+		}
+
+		field, ok := fieldByTOMLName(value, name)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("%w: %s", ErrUnknownKey, key)
+		}
+		value = field
+	}
+
+	if value.Kind() == reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("%w: %s", ErrUnknownKey, key)
+	}
+	if !settable(value.Type()) {
+		return reflect.Value{}, fmt.Errorf("%w: %s", ErrUnsupportedType, key)
+	}
+
+	return value, nil
+}
+
+func fieldByTOMLName(v reflect.Value, name string) (reflect.Value, bool) {
+	for i := range v.NumField() {
+		if tomlName(v.Type().Field(i)) == name {
+			return v.Field(i), true
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+func tomlName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("toml"), ",")
+	return name
+}
+
+func settable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	case reflect.Pointer:
+		return t.Elem().Kind() != reflect.Pointer && settable(t.Elem())
+	default:
+		return false
+	}
+}
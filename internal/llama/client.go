@@ -3,6 +3,7 @@ package llama
 type ProviderConfig struct {
 	baseURL string
 	model   string
+	options GenerationOptions
 }
 
 func NewOlamaProviderConfig(baseURL, model string) ProviderConfig {
@@ -29,3 +30,12 @@ func (o ProviderConfig) WithModel(model string) ProviderConfig {
 	o.model = model
 	return o
 }
+
+func (o ProviderConfig) Options() GenerationOptions {
+	return o.options
+}
+
+func (o ProviderConfig) WithOptions(options GenerationOptions) ProviderConfig {
+	o.options = options
+	return o
+}
@@ -11,35 +11,23 @@ import (
 
 const llamaPath = "ollama"
 
+// LoadTextToJSONProvider returns a provider of the ollama server at
+// baseURL, starting it if needed. An empty baseURL uses OLLAMA_URL.
 func LoadTextToJSONProvider(
+	baseURL string,
 	model string,
 ) (*TextToJSONProvider, error) {
-	var cmd *exec.Cmd
-	if !ollamaServerIsRunning() {
-		var err error
-		cmd, err = tryStartOllama()
-		if err != nil {
-			ollamaOutput := llamaPath
-			const maxDownloadRetries = 3
-			err = backoff.Retry(func() error {
-				fmt.Printf(
-					"Download ollama to %s\n",
-					ollamaOutput,
-				)
-				return downloadOllama(
-					context.TODO(),
-					ollamaOutput,
-				)
-			}, backoff.WithMaxRetries(backoff.NewConstantBackOff(time.Second), maxDownloadRetries))
-			if err != nil {
-				return nil, fmt.Errorf("error installing ollama: %w", err)
-			}
-		}
+	if baseURL == "" {
+		baseURL = getOllamaURL()
+	}
+
+	cmd, err := startOllama(baseURL)
+	if err != nil {
+		return nil, err
 	}
-	url := getOllamaURL()
 
 	ollamaConfig := NewOlamaProviderConfig(
-		url,
+		baseURL,
 		model,
 	)
 	p, err := NewTextToJSONProvider(
@@ -52,3 +40,53 @@ func LoadTextToJSONProvider(
 
 	return p, nil
 }
+
+// LoadModelManager returns a manager of the models of the ollama server
+// at baseURL, starting it if needed. An empty baseURL uses OLLAMA_URL.
+func LoadModelManager(baseURL string) (*ModelManager, error) {
+	if baseURL == "" {
+		baseURL = getOllamaURL()
+	}
+
+	cmd, err := startOllama(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := NewModelManager(baseURL, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("error creating model manager: %w", err)
+	}
+
+	return m, nil
+}
+
+// startOllama starts the ollama server unless it is already running,
+// installing it if needed. The returned command is nil when the server
+// was already running.
+func startOllama(baseURL string) (*exec.Cmd, error) {
+	if ollamaServerIsRunning(baseURL) {
+		return nil, nil
+	}
+
+	cmd, err := tryStartOllama()
+	if err != nil {
+		ollamaOutput := llamaPath
+		const maxDownloadRetries = 3
+		err = backoff.Retry(func() error {
+			fmt.Printf(
+				"Download ollama to %s\n",
+				ollamaOutput,
+			)
+			return downloadOllama(
+				context.TODO(),
+				ollamaOutput,
+			)
+		}, backoff.WithMaxRetries(backoff.NewConstantBackOff(time.Second), maxDownloadRetries))
+		if err != nil {
+			return nil, fmt.Errorf("error installing ollama: %w", err)
+		}
+	}
+
+	return cmd, nil
+}
@@ -0,0 +1,214 @@
+package llama
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+
+	"github.com/ollama/ollama/api"
+)
+
+// ErrModelNotFound is returned for models missing from the server.
+var ErrModelNotFound = errors.New("model not found")
+
+// PullProgressFunc receives the progress of each step of a pull.
+type PullProgressFunc func(status string, completed, total int64)
+
+// ModelInfo describes a model available on the server.
+type ModelInfo struct {
+	Name          string
+	Size          int64
+	Family        string
+	ParameterSize string
+	Quantization  string
+	// ContextLength is the maximum context of the model, zero if unknown.
+	ContextLength int
+	// JSON reports whether the model generates text, which the server
+	// can constrain to JSON.
+	JSON bool
+	// Tools reports whether the template of the model handles tool calls.
+	Tools bool
+}
+
+// ModelManager lists, pulls and removes the models of an ollama server.
+type ModelManager struct {
+	client *api.Client
+	cmd    *exec.Cmd
+}
+
+// NewModelManager returns a manager of the server at baseURL. The
+// command, if any, is the server started by us and stopped on Close.
+func NewModelManager(baseURL string, cmd *exec.Cmd) (*ModelManager, error) {
+	client, err := newOllamaClient(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd != nil {
+		if err := waitForOllamaServer(client); err != nil {
+			return nil, fmt.Errorf("error waiting for ollama server: %w", err)
+		}
+	}
+
+	return &ModelManager{
+		client: client,
+		cmd:    cmd,
+	}, nil
+}
+
+func (m ModelManager) Close() error {
+	if m.cmd != nil {
+		if err := stopOllamaServer(m.cmd); err != nil {
+			return fmt.Errorf("error stopping ollama server: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// List describes every local model.
+func (m ModelManager) List(ctx context.Context) ([]ModelInfo, error) {
+	listResp, err := m.client.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing models: %w", err)
+	}
+
+	models := make([]ModelInfo, 0, len(listResp.Models))
+	for _, model := range listResp.Models {
+		info, err := m.show(ctx, model)
+		if err != nil {
+			return nil, err
+		}
+		models = append(models, info)
+	}
+
+	return models, nil
+}
+
+// Names returns the names of the local models.
+func (m ModelManager) Names(ctx context.Context) ([]string, error) {
+	listResp, err := m.client.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing models: %w", err)
+	}
+
+	names := make([]string, 0, len(listResp.Models))
+	for _, model := range listResp.Models {
+		names = append(names, model.Name)
+	}
+
+	return names, nil
+}
+
+// Info describes a local model.
+func (m ModelManager) Info(ctx context.Context, name string) (ModelInfo, error) {
+	listResp, err := m.client.List(ctx)
+	if err != nil {
+		return ModelInfo{}, fmt.Errorf("error listing models: %w", err)
+	}
+
+	for _, model := range listResp.Models {
+		if model.Name == name || model.Name == name+":latest" {
+			return m.show(ctx, model)
+		}
+	}
+
+	return ModelInfo{}, fmt.Errorf("%w: %s", ErrModelNotFound, name)
+}
+
+// Pull downloads a model, reporting the progress of each step.
+func (m ModelManager) Pull(
+	ctx context.Context,
+	name string,
+	fn PullProgressFunc,
+) error {
+	return pullModel(ctx, m.client, name, fn)
+}
+
+// Remove deletes a local model.
+func (m ModelManager) Remove(ctx context.Context, name string) error {
+	if err := m.client.Delete(ctx, &api.DeleteRequest{Model: name}); err != nil {
+		return fmt.Errorf("error removing model: %w", err)
+	}
+
+	return nil
+}
+
+func (m ModelManager) show(
+	ctx context.Context,
+	model api.ListModelResponse,
+) (ModelInfo, error) {
+	showResp, err := m.client.Show(ctx, &api.ShowRequest{Model: model.Name})
+	if err != nil {
+		return ModelInfo{}, fmt.Errorf(
+			"error showing model %s: %w",
+			model.Name,
+			err,
+		)
+	}
+
+	return ModelInfo{
+		Name:          model.Name,
+		Size:          model.Size,
+		Family:        model.Details.Family,
+		ParameterSize: model.Details.ParameterSize,
+		Quantization:  model.Details.QuantizationLevel,
+		ContextLength: contextLength(showResp.ModelInfo),
+		JSON:          showResp.Template != "",
+		Tools:         strings.Contains(showResp.Template, ".Tools"),
+	}, nil
+}
+
+// contextLength reads the context length from the model metadata, where
+// it is keyed by the architecture of the model.
+func contextLength(modelInfo map[string]any) int {
+	arch, _ := modelInfo["general.architecture"].(string)
+	if arch == "" {
+		return 0
+	}
+
+	length, _ := modelInfo[arch+".context_length"].(float64)
+	return int(length)
+}
+
+func pullModel(
+	ctx context.Context,
+	client *api.Client,
+	name string,
+	fn PullProgressFunc,
+) error {
+	req := api.PullRequest{
+		Model:  name,
+		Stream: boolPtr(true),
+	}
+
+	progressCb := func(resp api.ProgressResponse) error {
+		if fn != nil {
+			fn(resp.Status, resp.Completed, resp.Total)
+		}
+		return nil
+	}
+
+	if err := client.Pull(ctx, &req, progressCb); err != nil {
+		return fmt.Errorf("error pulling model: %w", err)
+	}
+
+	return nil
+}
+
+func newOllamaClient(baseURL string) (*api.Client, error) {
+	httpClient := &http.Client{
+		Timeout: ollamaDefaultServerPullTimeout,
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing ollama url: %w", err)
+	}
+
+	return api.NewClient(u, httpClient), nil
+}
@@ -19,8 +19,8 @@ const (
 	ollamaServerTimeout = 10 * time.Minute
 )
 
-func ollamaServerIsRunning() bool {
-	defaultURL := "http://localhost:11434/health"
+func ollamaServerIsRunning(baseURL string) bool {
+	healthURL := strings.TrimSuffix(baseURL, "/") + "/health"
 	client := &http.Client{
 		Timeout: ollamaServerTimeout,
 	}
@@ -30,7 +30,7 @@ func ollamaServerIsRunning() bool {
 	)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, defaultURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL, nil)
 	if err != nil {
 		return false
 	}
@@ -178,6 +178,7 @@ func downloadOllama(
 	return fmt.Errorf("ollama binary downloaded to %s", path)
 }
 
+// getOllamaURL returns the server address used when none is configured.
 func getOllamaURL() string {
 	if os.Getenv("OLLAMA_URL") != "" {
 		return os.Getenv("OLLAMA_URL")
@@ -0,0 +1,46 @@
+package llama
+
+import (
+	"time"
+
+	"github.com/ollama/ollama/api"
+)
+
+// GenerationOptions tunes the sampling of completions. Nil values keep
+// the defaults of the server.
+type GenerationOptions struct {
+	Temperature *float64
+	TopP        *float64
+	NumCtx      *int
+	Seed        *int
+	// KeepAlive is how long the model stays loaded after a request. A
+	// negative duration keeps it loaded.
+	KeepAlive *time.Duration
+}
+
+// options returns the model options of a request.
+func (o GenerationOptions) options() map[string]any {
+	opts := make(map[string]any)
+	if o.Temperature != nil {
+		opts["temperature"] = *o.Temperature
+	}
+	if o.TopP != nil {
+		opts["top_p"] = *o.TopP
+	}
+	if o.NumCtx != nil {
+		opts["num_ctx"] = *o.NumCtx
+	}
+	if o.Seed != nil {
+		opts["seed"] = *o.Seed
+	}
+
+	return opts
+}
+
+func (o GenerationOptions) keepAlive() *api.Duration {
+	if o.KeepAlive == nil {
+		return nil
+	}
+
+	return &api.Duration{Duration: *o.KeepAlive}
+}
@@ -3,14 +3,13 @@ package llama
 import (
 	"context"
 	"fmt"
-	"net/http"
-	"net/url"
+	"os"
 	"os/exec"
 	"time"
 
-	"github.com/dustin/go-humanize"
 	"github.com/nullswan/llama-hackaton/internal/chat"
 	"github.com/nullswan/llama-hackaton/internal/completion"
+	"github.com/nullswan/llama-hackaton/internal/term"
 	"github.com/ollama/ollama/api"
 )
 
@@ -20,6 +19,17 @@ const (
 	ollamaDefaultServerPullTimeout   = 10 * time.Minute
 )
 
+// DefaultModel returns the model used for code generation and retries.
+func DefaultModel() string {
+	return ollamaTextToJSONDefaultModel
+}
+
+// DefaultFastModel returns the model used for questions and simple
+// requests.
+func DefaultFastModel() string {
+	return ollamaTextToJSONDefaultModelFast
+}
+
 type TextToJSONProvider struct {
 	config ProviderConfig
 	client *api.Client
@@ -31,13 +41,9 @@ func NewTextToJSONProvider(
 	config ProviderConfig,
 	cmd *exec.Cmd,
 ) (*TextToJSONProvider, error) {
-	httpClient := &http.Client{
-		Timeout: ollamaDefaultServerPullTimeout,
-	}
-
-	url, err := url.Parse(config.BaseURL())
+	client, err := newOllamaClient(config.BaseURL())
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	if config.model == "" {
@@ -47,11 +53,8 @@ func NewTextToJSONProvider(
 	// TODO(nullswan): Mutualize start code
 	p := &TextToJSONProvider{
 		config: config,
-		client: api.NewClient(
-			url,
-			httpClient,
-		),
-		cmd: cmd,
+		client: client,
+		cmd:    cmd,
 	}
 
 	if cmd != nil {
@@ -61,36 +64,62 @@ func NewTextToJSONProvider(
 		}
 	}
 
+	if err := p.ensureModel(context.TODO(), config.model); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// WithModel returns a provider sharing the same server but using another
+// model, which is pulled if needed. Closing it does not stop the server.
+func (p *TextToJSONProvider) WithModel(
+	ctx context.Context,
+	model string,
+) (*TextToJSONProvider, error) {
+	if err := p.ensureModel(ctx, model); err != nil {
+		return nil, err
+	}
+
+	return &TextToJSONProvider{
+		config: p.config.WithModel(model),
+		client: p.client,
+	}, nil
+}
+
+// WithOptions returns a provider sharing the same server and model but
+// generating with other options. Closing it does not stop the server.
+func (p *TextToJSONProvider) WithOptions(
+	options GenerationOptions,
+) *TextToJSONProvider {
+	return &TextToJSONProvider{
+		config: p.config.WithOptions(options),
+		client: p.client,
+	}
+}
+
+// ensureModel pulls the model unless it is already available locally.
+func (p *TextToJSONProvider) ensureModel(
+	ctx context.Context,
+	model string,
+) error {
 	for {
-		listResp, err := p.client.List(context.TODO())
+		listResp, err := p.client.List(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("error listing models: %w", err)
+			return fmt.Errorf("error listing models: %w", err)
 		}
-		for _, model := range listResp.Models {
-			if model.Name == config.model {
-				return p, nil
+		for _, m := range listResp.Models {
+			if m.Name == model {
+				return nil
 			}
 		}
 
-		req := api.PullRequest{
-			Model:  config.model,
-			Stream: boolPtr(true),
-		}
-
-		progressCb := func(resp api.ProgressResponse) error {
-			fmt.Printf(
-				"Pulling %q: %s [%s/%s]\n",
-				config.model,
-				resp.Status,
-				humanize.Bytes(uint64(resp.Completed)),
-				humanize.Bytes(uint64(resp.Total)),
-			)
-			return nil
-		}
-
-		err = p.client.Pull(context.TODO(), &req, progressCb)
+		fmt.Printf("Pulling %q\n", model)
+		bar := term.NewProgressBar(os.Stdout)
+		err = pullModel(ctx, p.client, model, bar.Update)
+		bar.Done()
 		if err != nil {
-			return nil, fmt.Errorf("error pulling model: %w", err)
+			return err
 		}
 	}
 }
@@ -111,12 +140,34 @@ func (p TextToJSONProvider) GetModel() string {
 	return p.config.model
 }
 
+// Warmup loads the model in memory so the first completion does not pay
+// for it.
+func (p TextToJSONProvider) Warmup(ctx context.Context) error {
+	req := api.ChatRequest{
+		Model:     p.config.model,
+		KeepAlive: p.config.options.keepAlive(),
+	}
+
+	err := p.client.Chat(ctx, &req, func(api.ChatResponse) error {
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error loading model: %w", err)
+	}
+
+	return nil
+}
+
 func (p TextToJSONProvider) GenerateCompletion(
 	ctx context.Context,
 	messages []chat.Message,
 	completionCh chan<- completion.Completion,
 ) error {
-	req := completionRequestTextToJSON(p.config.model, messages)
+	req := completionRequestTextToJSON(
+		p.config.model,
+		p.config.options,
+		messages,
+	)
 
 	aggCompletion := ""
 	resp := func(resp api.ChatResponse) error {
@@ -147,15 +198,18 @@ func (p TextToJSONProvider) GenerateCompletion(
 
 func completionRequestTextToJSON(
 	model string,
+	options GenerationOptions,
 	messages []chat.Message,
 ) api.ChatRequest {
 	stream := true
 
 	req := api.ChatRequest{
-		Model:    model,
-		Stream:   &stream,
-		Messages: make([]api.Message, len(messages)),
-		Format:   "json",
+		Model:     model,
+		Stream:    &stream,
+		Messages:  make([]api.Message, len(messages)),
+		Format:    "json",
+		KeepAlive: options.keepAlive(),
+		Options:   options.options(),
 	}
 
 	for i, m := range messages {
@@ -10,13 +10,14 @@ type Logger = slog.Logger
 
 var (
 	logger *slog.Logger
+	level  = new(slog.LevelVar)
 	once   sync.Once
 )
 
 func initInstance() {
-	level := slog.LevelError
+	level.Set(slog.LevelError)
 	if os.Getenv("DEBUG") != "" {
-		level = slog.LevelDebug
+		level.Set(slog.LevelDebug)
 	}
 
 	loggerHandlerOpts := &slog.HandlerOptions{
@@ -35,3 +36,14 @@ func Init() *slog.Logger {
 
 	return logger
 }
+
+// SetDebug enables or disables the debug logs.
+func SetDebug(debug bool) {
+	Init()
+
+	if debug {
+		level.Set(slog.LevelDebug)
+	} else {
+		level.Set(slog.LevelError)
+	}
+}
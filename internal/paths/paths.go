@@ -0,0 +1,45 @@
+package paths
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const appName = "nomi"
+
+// DataDir returns the directory where nomi stores its persistent data,
+// creating it if needed. It honors XDG_DATA_HOME and falls back to
+// ~/.local/share/nomi.
+func DataDir() (string, error) {
+	base := os.Getenv("XDG_DATA_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("error getting home directory: %w", err)
+		}
+		base = filepath.Join(home, ".local", "share")
+	}
+
+	dir := filepath.Join(base, appName)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("error creating data directory: %w", err)
+	}
+
+	return dir, nil
+}
+
+// ConfigDir returns the directory holding the nomi configuration files.
+// It honors XDG_CONFIG_HOME and falls back to ~/.config/nomi.
+func ConfigDir() (string, error) {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("error getting home directory: %w", err)
+		}
+		base = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(base, appName), nil
+}
@@ -0,0 +1,96 @@
+package probe
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxListedEntries bounds the entries named in a directory summary.
+const maxListedEntries = 8
+
+// projectMarkers are files telling what kind of project a directory is.
+var projectMarkers = map[string]string{
+	".git":             "git repository",
+	"go.mod":           "Go module",
+	"package.json":     "Node.js project",
+	"pyproject.toml":   "Python project",
+	"requirements.txt": "Python project",
+	"Cargo.toml":       "Rust crate",
+	"Gemfile":          "Ruby project",
+	"pom.xml":          "Maven project",
+	"Makefile":         "Makefile",
+	"Dockerfile":       "Dockerfile",
+}
+
+// DirSummary briefly describes a directory.
+type DirSummary struct {
+	Path    string
+	Files   int
+	Dirs    int
+	Kinds   []string
+	Entries []string
+}
+
+// SummarizeDir lists a directory without descending into it. Unreadable
+// directories only report their path.
+func SummarizeDir(dir string) DirSummary {
+	summary := DirSummary{Path: dir}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return summary
+	}
+
+	kinds := make(map[string]struct{})
+	for _, e := range entries {
+		if kind, ok := projectMarkers[e.Name()]; ok {
+			kinds[kind] = struct{}{}
+		}
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+
+		name := e.Name()
+		if e.IsDir() {
+			summary.Dirs++
+			name += string(filepath.Separator)
+		} else {
+			summary.Files++
+		}
+		if len(summary.Entries) < maxListedEntries {
+			summary.Entries = append(summary.Entries, name)
+		}
+	}
+
+	for kind := range kinds {
+		summary.Kinds = append(summary.Kinds, kind)
+	}
+	sort.Strings(summary.Kinds)
+
+	return summary
+}
+
+func (d DirSummary) String() string {
+	if d.Path == "" {
+		return ""
+	}
+
+	details := append([]string{}, d.Kinds...)
+	details = append(
+		details,
+		strconv.Itoa(d.Files)+" files, "+strconv.Itoa(d.Dirs)+" directories",
+	)
+
+	out := d.Path + " (" + strings.Join(details, "; ") + ")"
+	if len(d.Entries) > 0 {
+		out += ": " + strings.Join(d.Entries, ", ")
+		if more := d.Files + d.Dirs - len(d.Entries); more > 0 {
+			out += " and " + strconv.Itoa(more) + " more"
+		}
+	}
+
+	return out
+}
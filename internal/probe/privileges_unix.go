@@ -0,0 +1,13 @@
+//go:build !windows
+
+package probe
+
+import "os"
+
+func privileges() string {
+	if os.Geteuid() == 0 {
+		return "root"
+	}
+
+	return "user"
+}
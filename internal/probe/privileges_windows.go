@@ -0,0 +1,13 @@
+//go:build windows
+
+package probe
+
+import "golang.org/x/sys/windows"
+
+func privileges() string {
+	if windows.GetCurrentProcessToken().IsElevated() {
+		return "administrator"
+	}
+
+	return "user"
+}
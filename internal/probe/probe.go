@@ -0,0 +1,212 @@
+package probe
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// commandTimeout bounds each version query, so a misbehaving binary
+// cannot delay the startup.
+const commandTimeout = 2 * time.Second
+
+var (
+	packageManagers = []string{
+		"apt", "dnf", "yum", "pacman", "zypper", "apk", "nix",
+		"brew", "port", "winget", "choco", "scoop", "snap", "flatpak",
+	}
+	commonCLIs = []string{
+		"git", "curl", "wget", "jq", "make", "docker", "podman",
+		"kubectl", "gh", "ssh", "rsync", "tar", "unzip", "ffmpeg",
+		"systemctl", "sudo",
+	}
+	// interpreters maps the interpreters to the arguments printing their
+	// version.
+	interpreters = []interpreter{
+		{name: "python3", args: []string{"--version"}},
+		{name: "node", args: []string{"--version"}},
+		{name: "ruby", args: []string{"--version"}},
+		{name: "perl", args: []string{"-e", "print $^V"}},
+		{name: "go", args: []string{"version"}},
+		{name: "java", args: []string{"-version"}},
+		{name: "php", args: []string{"--version"}},
+		{name: "pwsh", args: []string{"--version"}},
+	}
+)
+
+var versionPattern = regexp.MustCompile(`\d+\.\d+(\.\d+)?`)
+
+type interpreter struct {
+	name string
+	args []string
+}
+
+// Facts describe the machine the scripts run on.
+type Facts struct {
+	OS     string
+	Arch   string
+	Distro string
+	Shell  string
+	// PackageManagers, Interpreters and CLIs are the tools found in PATH,
+	// interpreters with their version when it could be read.
+	PackageManagers []string
+	Interpreters    []string
+	CLIs            []string
+	Dir             DirSummary
+	// Privileges is root, administrator or user.
+	Privileges string
+}
+
+// Probe inspects the machine and the directory scripts start in.
+func Probe(ctx context.Context, dir string) Facts {
+	return Facts{
+		OS:              runtime.GOOS,
+		Arch:            runtime.GOARCH,
+		Distro:          distro(ctx),
+		Shell:           shell(),
+		PackageManagers: available(packageManagers),
+		Interpreters:    interpreterVersions(ctx),
+		CLIs:            available(commonCLIs),
+		Dir:             SummarizeDir(dir),
+		Privileges:      privileges(),
+	}
+}
+
+// String formats the facts as a compact block for the system prompt.
+func (f Facts) String() string {
+	var sb strings.Builder
+
+	system := f.OS + "/" + f.Arch
+	if f.Distro != "" {
+		system += ", " + f.Distro
+	}
+	writeFact(&sb, "System", system)
+	writeFact(&sb, "Shell", f.Shell)
+	writeFact(&sb, "Package managers", strings.Join(f.PackageManagers, ", "))
+	writeFact(&sb, "Interpreters", strings.Join(f.Interpreters, ", "))
+	writeFact(&sb, "Tools", strings.Join(f.CLIs, ", "))
+	writeFact(&sb, "Working directory", f.Dir.String())
+	writeFact(&sb, "Privileges", f.Privileges)
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func writeFact(sb *strings.Builder, name, value string) {
+	if value == "" {
+		return
+	}
+
+	sb.WriteString("- " + name + ": " + value + "\n")
+}
+
+func available(names []string) []string {
+	var found []string
+	for _, name := range names {
+		if _, err := exec.LookPath(name); err == nil {
+			found = append(found, name)
+		}
+	}
+
+	return found
+}
+
+// interpreterVersions queries the version of the interpreters found in
+// PATH concurrently.
+func interpreterVersions(ctx context.Context) []string {
+	versions := make([]string, len(interpreters))
+
+	var wg sync.WaitGroup
+	for i, interp := range interpreters {
+		if _, err := exec.LookPath(interp.name); err != nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			versions[i] = interp.name
+			if v := parseVersion(output(ctx, interp.name, interp.args...)); v != "" {
+				versions[i] += " " + v
+			}
+		}()
+	}
+	wg.Wait()
+
+	found := versions[:0]
+	for _, v := range versions {
+		if v != "" {
+			found = append(found, v)
+		}
+	}
+
+	return found
+}
+
+// parseVersion extracts the first version number of a version banner.
+func parseVersion(banner string) string {
+	return versionPattern.FindString(banner)
+}
+
+// output runs a command and returns its combined output, empty on
+// failure.
+func output(ctx context.Context, name string, args ...string) string {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+// distro returns the name and version of the distribution or release of
+// the operating system.
+func distro(ctx context.Context) string {
+	switch runtime.GOOS {
+	case "linux":
+		return osRelease("/etc/os-release")
+	case "darwin":
+		if v := output(ctx, "sw_vers", "-productVersion"); v != "" {
+			return "macOS " + v
+		}
+	}
+
+	return ""
+}
+
+func osRelease(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if v, ok := strings.CutPrefix(scanner.Text(), "PRETTY_NAME="); ok {
+			return strings.Trim(v, `"'`)
+		}
+	}
+
+	return ""
+}
+
+func shell() string {
+	if sh := os.Getenv("SHELL"); sh != "" {
+		return sh
+	}
+	if runtime.GOOS == "windows" {
+		return os.Getenv("ComSpec")
+	}
+
+	return ""
+}
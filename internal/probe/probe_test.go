@@ -0,0 +1,100 @@
+package probe
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		banner   string
+		expected string
+	}{
+		{banner: "Python 3.11.2", expected: "3.11.2"},
+		{banner: "v20.11.1", expected: "20.11.1"},
+		{banner: "go version go1.23.2 linux/amd64", expected: "1.23.2"},
+		{banner: `openjdk version "17.0.9" 2023-10-17`, expected: "17.0.9"},
+		{banner: "ruby 3.2", expected: "3.2"},
+		{banner: "", expected: ""},
+		{banner: "no version here", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.banner, func(t *testing.T) {
+			t.Parallel()
+
+			if got := parseVersion(tt.banner); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSummarizeDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"go.mod", "main.go", ".env"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+	for _, name := range []string{".git", "internal"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0o700); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+
+	got := SummarizeDir(dir).String()
+	expected := dir + " (Go module; git repository; 2 files, 1 directories): go.mod, internal" +
+		string(filepath.Separator) + ", main.go"
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestSummarizeDirTruncates(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for i := range maxListedEntries + 2 {
+		name := filepath.Join(dir, "file"+string(rune('a'+i)))
+		if err := os.WriteFile(name, nil, 0o600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	summary := SummarizeDir(dir)
+	if len(summary.Entries) != maxListedEntries {
+		t.Errorf("Expected %d entries, got %d", maxListedEntries, len(summary.Entries))
+	}
+	if got := summary.String(); got[len(got)-len("and 2 more"):] != "and 2 more" {
+		t.Errorf("Expected the summary to count the other entries, got %q", got)
+	}
+}
+
+func TestFactsString(t *testing.T) {
+	t.Parallel()
+
+	facts := Facts{
+		OS:              "linux",
+		Arch:            "amd64",
+		Distro:          "Debian GNU/Linux 12",
+		Shell:           "/bin/bash",
+		PackageManagers: []string{"apt"},
+		Interpreters:    []string{"python3 3.11.2"},
+		Privileges:      "user",
+	}
+
+	expected := `- System: linux/amd64, Debian GNU/Linux 12
+- Shell: /bin/bash
+- Package managers: apt
+- Interpreters: python3 3.11.2
+- Privileges: user`
+	if got := facts.String(); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
@@ -0,0 +1,207 @@
+package prompts
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const (
+	extension   = ".tmpl"
+	consoleName = "console"
+	// partialName is a template available to every other one.
+	partialName = "environment"
+)
+
+//go:embed templates/*.tmpl
+var builtin embed.FS
+
+var ErrNotFound = errors.New("prompt not found")
+
+// Vars are the variables available to the templates.
+type Vars struct {
+	// OS is the display name of the operating system, like macOS.
+	OS string
+	// GOOS is the operating system as reported by runtime.GOOS.
+	GOOS      string
+	Distro    string
+	Shell     string
+	Languages []string
+	Cwd       string
+	Username  string
+	// Facts is a compact description of the machine, one fact per line.
+	Facts string
+
+	// Request is the user request being handled.
+	Request string
+	// Failures is the number of failed attempts at the request.
+	Failures int
+}
+
+// Prompt is a template and where it was loaded from.
+type Prompt struct {
+	Name string
+	// Path is the file overriding the built-in template, if any.
+	Path string
+	Text string
+}
+
+// Builtin reports whether the prompt is the embedded default.
+func (p Prompt) Builtin() bool {
+	return p.Path == ""
+}
+
+// Store looks up templates in override directories before falling back
+// to the built-in ones.
+type Store struct {
+	dirs []string
+}
+
+// NewStore returns a store searching the directories in order.
+func NewStore(dirs ...string) *Store {
+	return &Store{
+		dirs: dirs,
+	}
+}
+
+// List returns every prompt, overridden or not, sorted by name.
+func (s *Store) List() ([]Prompt, error) {
+	names := make(map[string]struct{})
+
+	entries, err := fs.ReadDir(builtin, "templates")
+	if err != nil {
+		return nil, fmt.Errorf("error reading built-in prompts: %w", err)
+	}
+	for _, e := range entries {
+		names[strings.TrimSuffix(e.Name(), extension)] = struct{}{}
+	}
+
+	for _, dir := range s.dirs {
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading prompts of %s: %w", dir, err)
+		}
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasSuffix(e.Name(), extension) {
+				names[strings.TrimSuffix(e.Name(), extension)] = struct{}{}
+			}
+		}
+	}
+
+	prompts := make([]Prompt, 0, len(names))
+	for name := range names {
+		p, err := s.Lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		prompts = append(prompts, p)
+	}
+	sort.Slice(prompts, func(i, j int) bool {
+		return prompts[i].Name < prompts[j].Name
+	})
+
+	return prompts, nil
+}
+
+// Lookup returns the template of a prompt.
+func (s *Store) Lookup(name string) (Prompt, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return Prompt{}, fmt.Errorf("%w: %q", ErrNotFound, name)
+	}
+
+	for _, dir := range s.dirs {
+		path := filepath.Join(dir, name+extension)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Prompt{}, fmt.Errorf("error reading prompt %s: %w", path, err)
+		}
+
+		return Prompt{Name: name, Path: path, Text: string(data)}, nil
+	}
+
+	data, err := builtin.ReadFile("templates/" + name + extension)
+	if err != nil {
+		return Prompt{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	return Prompt{Name: name, Text: string(data)}, nil
+}
+
+// Render executes the template of a prompt.
+func (s *Store) Render(name string, vars Vars) (string, error) {
+	p, err := s.Lookup(name)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := s.parse(p)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, vars); err != nil {
+		return "", fmt.Errorf("error rendering prompt %s: %w", name, err)
+	}
+
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+// RenderConsole renders the system prompt of the console, preferring the
+// variant of the operating system, like console_linux.
+func (s *Store) RenderConsole(vars Vars) (string, error) {
+	name := consoleName + "_" + vars.GOOS
+	if _, err := s.Lookup(name); errors.Is(err, ErrNotFound) {
+		name = consoleName
+	}
+
+	return s.Render(name, vars)
+}
+
+// Validate checks that a template parses.
+func (s *Store) Validate(name, text string) error {
+	_, err := s.parse(Prompt{Name: name, Text: text})
+	return err
+}
+
+func (s *Store) parse(p Prompt) (*template.Template, error) {
+	tmpl := template.New(p.Name).Funcs(funcs)
+
+	if p.Name != partialName {
+		partial, err := s.Lookup(partialName)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tmpl.New(partialName).Parse(partial.Text); err != nil {
+			return nil, fmt.Errorf("error parsing prompt %s: %w", partialName, err)
+		}
+	}
+
+	if _, err := tmpl.Parse(p.Text); err != nil {
+		return nil, fmt.Errorf("error parsing prompt %s: %w", p.Name, err)
+	}
+
+	return tmpl, nil
+}
+
+var funcs = template.FuncMap{
+	"join":      strings.Join,
+	"quoteList": quoteList,
+}
+
+// quoteList formats a list as 'a', 'b', 'c'.
+func quoteList(items []string) string {
+	return "'" + strings.Join(items, "', '") + "'"
+}
@@ -0,0 +1,162 @@
+package prompts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderConsoleBuiltin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		vars     Vars
+		expected []string
+	}{
+		{
+			name: "Linux",
+			vars: Vars{
+				OS:        "Linux",
+				GOOS:      "linux",
+				Distro:    "Debian GNU/Linux 12",
+				Shell:     "bash",
+				Languages: []string{"bash", "python"},
+				Cwd:       "/home/nomi",
+				Username:  "nomi",
+				Facts:     "- Package managers: apt",
+			},
+			expected: []string{
+				"# Environment\n\n- Package managers: apt\n\nIf generating code",
+				"Linux machine (Debian GNU/Linux 12)",
+				"'bash', 'python'",
+				"The shell of the user is bash.",
+				"The current directory is /home/nomi.",
+				"The user is logged in as nomi.",
+			},
+		},
+		{
+			name: "macOS",
+			vars: Vars{
+				OS:        "macOS",
+				GOOS:      "darwin",
+				Languages: []string{"osascript", "zsh"},
+			},
+			expected: []string{
+				"macOS machine.",
+				"'osascript', 'zsh'",
+			},
+		},
+		{
+			name: "Other OS",
+			vars: Vars{
+				OS:        "Windows",
+				GOOS:      "windows",
+				Languages: []string{"powershell"},
+			},
+			expected: []string{
+				"Windows machine.",
+				`"language": "powershell"`,
+			},
+		},
+		{
+			name: "No language",
+			vars: Vars{
+				OS:   "FreeBSD",
+				GOOS: "freebsd",
+			},
+			expected: []string{
+				"FreeBSD machine.",
+				`"language": "python"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewStore().RenderConsole(tt.vars)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(got, want) {
+					t.Errorf("Expected prompt to contain %q", want)
+				}
+			}
+			if strings.Contains(got, "{{") {
+				t.Errorf("Expected no template left in prompt")
+			}
+		})
+	}
+}
+
+func TestOverride(t *testing.T) {
+	t.Parallel()
+
+	user := t.TempDir()
+	project := t.TempDir()
+	writePrompt(t, user, "summary", "user {{ .Request }}")
+	writePrompt(t, user, "custom", "custom prompt")
+	writePrompt(t, project, "summary", "project {{ .Request }}")
+	writePrompt(t, project, "notes", "project notes")
+
+	store := NewStore(user, project)
+
+	got, err := store.Render("summary", Vars{Request: "ls"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got != "user ls" {
+		t.Errorf("Expected the user prompt, got %q", got)
+	}
+
+	prompts, err := store.List()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sources := make(map[string]string)
+	for _, p := range prompts {
+		sources[p.Name] = p.Path
+	}
+	if sources["summary"] != filepath.Join(user, "summary.tmpl") {
+		t.Errorf("Unexpected source of summary: %q", sources["summary"])
+	}
+	if sources["custom"] != filepath.Join(user, "custom.tmpl") {
+		t.Errorf("Unexpected source of custom: %q", sources["custom"])
+	}
+	if sources["notes"] != filepath.Join(project, "notes.tmpl") {
+		t.Errorf("Unexpected source of notes: %q", sources["notes"])
+	}
+	if p, ok := sources["verify"]; !ok || p != "" {
+		t.Errorf("Expected the built-in verify prompt, got %q", p)
+	}
+}
+
+func TestLookupErrors(t *testing.T) {
+	t.Parallel()
+
+	store := NewStore(t.TempDir())
+	for _, name := range []string{"missing", "", "../console"} {
+		if _, err := store.Lookup(name); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for %q, got %v", name, err)
+		}
+	}
+
+	if err := store.Validate("broken", "{{ .Request"); err == nil {
+		t.Errorf("Expected a parse error")
+	}
+}
+
+func writePrompt(t *testing.T, dir, name, text string) {
+	t.Helper()
+
+	path := filepath.Join(dir, name+extension)
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		t.Fatalf("Failed to write prompt: %v", err)
+	}
+}
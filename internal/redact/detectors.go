@@ -0,0 +1,107 @@
+package redact
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+)
+
+// Detector finds secrets in a text. When the pattern has capture groups,
+// only the first non-empty group is redacted, so surrounding context
+// such as a variable name is preserved for the model.
+type Detector struct {
+	Name    string
+	Pattern *regexp.Regexp
+	// Filter, when set, rejects candidates that are not secrets.
+	Filter func(string) bool
+}
+
+const (
+	minEntropyLength = 20
+	minEntropyBits   = 4.2
+)
+
+func builtinDetectors() []Detector {
+	return []Detector{
+		{
+			Name: "private_key",
+			Pattern: regexp.MustCompile(
+				`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`,
+			),
+		},
+		{
+			Name:    "aws_access_key",
+			Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+		},
+		{
+			Name: "aws_secret_key",
+			Pattern: regexp.MustCompile(
+				`(?i)aws_secret_access_key["']?\s*[=:]\s*["']?([A-Za-z0-9/+=]{40})`,
+			),
+		},
+		{
+			Name: "jwt",
+			Pattern: regexp.MustCompile(
+				`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`,
+			),
+		},
+		{
+			Name: "env_secret",
+			Pattern: regexp.MustCompile(
+				`(?i)\b[A-Z0-9_]*(?:PASSWORD|PASSWD|SECRET|TOKEN|API_?KEY|ACCESS_KEY|PRIVATE_KEY|CREDENTIALS?)[A-Z0-9_]*["']?\s*[=:]\s*["']?([^\s"']+)`,
+			),
+		},
+		{
+			Name:    "netrc_password",
+			Pattern: regexp.MustCompile(`(?m)\bpassword[ \t]+(\S+)`),
+		},
+		{
+			Name: "high_entropy",
+			Pattern: regexp.MustCompile(
+				`[A-Za-z0-9+/_=-]{` + strconv.Itoa(minEntropyLength) + `,}`,
+			),
+			Filter: isHighEntropy,
+		},
+	}
+}
+
+// isHighEntropy reports whether s looks like a random token.
+// Hex digests and identifiers are excluded by requiring mixed case and digits.
+func isHighEntropy(s string) bool {
+	if len(s) < minEntropyLength {
+		return false
+	}
+
+	var hasUpper, hasLower, hasDigit bool
+	for _, c := range s {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			hasUpper = true
+		case c >= 'a' && c <= 'z':
+			hasLower = true
+		case c >= '0' && c <= '9':
+			hasDigit = true
+		}
+	}
+	if !hasUpper || !hasLower || !hasDigit {
+		return false
+	}
+
+	return shannonEntropy(s) >= minEntropyBits
+}
+
+func shannonEntropy(s string) float64 {
+	freq := make(map[rune]int)
+	for _, c := range s {
+		freq[c]++
+	}
+
+	var entropy float64
+	length := float64(len(s))
+	for _, n := range freq {
+		p := float64(n) / length
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
@@ -0,0 +1,144 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	customDetectorName = "custom"
+	placeholderPrefix  = "[REDACTED_"
+)
+
+// Redactor replaces secrets with stable placeholders.
+// The same secret always maps to the same placeholder for the lifetime
+// of the Redactor, so the model can still refer to it consistently.
+type Redactor struct {
+	mu           sync.Mutex
+	detectors    []Detector
+	placeholders map[string]string
+	secrets      map[string]string
+	counters     map[string]int
+}
+
+// New creates a Redactor with the built-in detectors and the given
+// user-defined regular expressions.
+func New(patterns ...string) (*Redactor, error) {
+	detectors := builtinDetectors()
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", p, err)
+		}
+
+		// Custom patterns run first so they take precedence.
+		detectors = append([]Detector{{
+			Name:    customDetectorName,
+			Pattern: re,
+		}}, detectors...)
+	}
+
+	return &Redactor{
+		detectors:    detectors,
+		placeholders: make(map[string]string),
+		secrets:      make(map[string]string),
+		counters:     make(map[string]int),
+	}, nil
+}
+
+// Redact returns s with every detected secret replaced by its placeholder.
+func (r *Redactor) Redact(s string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, d := range r.detectors {
+		s = r.apply(d, s)
+	}
+
+	return s
+}
+
+// Restore replaces placeholders with the secrets they stand for.
+// It is used on model output so that generated code can reference
+// secrets the model never saw.
+func (r *Redactor) Restore(s string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for placeholder, secret := range r.secrets {
+		s = strings.ReplaceAll(s, placeholder, secret)
+	}
+
+	return s
+}
+
+// Contains reports whether s holds a secret.
+func (r *Redactor) Contains(s string) bool {
+	for _, d := range r.detectors {
+		for _, m := range d.Pattern.FindAllStringSubmatchIndex(s, -1) {
+			start, end := secretBounds(m)
+			secret := s[start:end]
+			if secret != "" && !strings.Contains(secret, placeholderPrefix) &&
+				(d.Filter == nil || d.Filter(secret)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (r *Redactor) apply(d Detector, s string) string {
+	matches := d.Pattern.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := secretBounds(m)
+		secret := s[start:end]
+		if secret == "" || strings.Contains(secret, placeholderPrefix) ||
+			(d.Filter != nil && !d.Filter(secret)) {
+			continue
+		}
+
+		sb.WriteString(s[last:start])
+		sb.WriteString(r.placeholder(d.Name, secret))
+		last = end
+	}
+	sb.WriteString(s[last:])
+
+	return sb.String()
+}
+
+// secretBounds returns the first non-empty capture group of a match,
+// or the whole match when the pattern has no groups.
+func secretBounds(m []int) (int, int) {
+	for i := 2; i+1 < len(m); i += 2 {
+		if m[i] >= 0 && m[i+1] > m[i] {
+			return m[i], m[i+1]
+		}
+	}
+
+	return m[0], m[1]
+}
+
+func (r *Redactor) placeholder(name, secret string) string {
+	if p, ok := r.placeholders[secret]; ok {
+		return p
+	}
+
+	r.counters[name]++
+	p := placeholderPrefix + strings.ToUpper(name) + "_" +
+		strconv.Itoa(r.counters[name]) + "]"
+
+	r.placeholders[secret] = p
+	r.secrets[p] = secret
+
+	return p
+}
@@ -5,6 +5,7 @@ package term
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/emirpasic/gods/lists/arraylist"
 	"github.com/mattn/go-runewidth"
@@ -21,6 +22,13 @@ type Buffer struct {
 	LineWidth    int
 	Width        int
 	Height       int
+	// KillRing, when set, receives the killed text.
+	KillRing *KillRing
+
+	undo     []bufferState
+	lastEdit editKind
+	// yanked is the length of the text inserted by the last yank.
+	yanked int
 }
 
 func NewBuffer(prompt *Prompt) (*Buffer, error) {
@@ -61,7 +69,7 @@ func (b *Buffer) MoveLeft() {
 		// asserts that we retrieve a rune
 		if e, ok := b.Buf.Get(b.Pos - 1); ok {
 			if r, ok := e.(rune); ok {
-				rLength := runewidth.RuneWidth(r)
+				rLength := runeWidth(r)
 
 				if b.DisplayPos%b.LineWidth == 0 {
 					fmt.Print(CursorUp + CursorBOL + CursorRightN(b.Width))
@@ -111,7 +119,7 @@ func (b *Buffer) MoveRight() {
 	if b.Pos < b.Buf.Size() {
 		if e, ok := b.Buf.Get(b.Pos); ok {
 			if r, ok := e.(rune); ok {
-				rLength := runewidth.RuneWidth(r)
+				rLength := runeWidth(r)
 				b.Pos += 1
 				hasSpace := b.GetLineSpacing(b.DisplayPos / b.LineWidth)
 				b.DisplayPos += rLength
@@ -192,7 +200,7 @@ func (b *Buffer) DisplaySize() int {
 	for i := range b.Buf.Size() {
 		if e, ok := b.Buf.Get(i); ok {
 			if r, ok := e.(rune); ok {
-				sum += runewidth.RuneWidth(r)
+				sum += runeWidth(r)
 			}
 		}
 	}
@@ -209,12 +217,12 @@ func (b *Buffer) Add(r rune) {
 }
 
 func (b *Buffer) AddChar(r rune, insert bool) {
-	rLength := runewidth.RuneWidth(r)
+	rLength := runeWidth(r)
 	b.DisplayPos += rLength
 
 	if b.Pos > 0 {
 		if b.DisplayPos%b.LineWidth == 0 {
-			fmt.Printf("%c", r)
+			fmt.Print(string(displayRune(r)))
 			fmt.Printf("\n%s", b.Prompt.AltPrompt)
 
 			if insert {
@@ -230,7 +238,7 @@ func (b *Buffer) AddChar(r rune, insert bool) {
 			}
 			fmt.Printf("\n%s", b.Prompt.AltPrompt)
 			b.DisplayPos += 1
-			fmt.Printf("%c", r)
+			fmt.Print(string(displayRune(r)))
 
 			if insert {
 				b.LineHasSpace.Set(b.DisplayPos/b.LineWidth-1, true)
@@ -238,10 +246,10 @@ func (b *Buffer) AddChar(r rune, insert bool) {
 				b.LineHasSpace.Add(true)
 			}
 		} else {
-			fmt.Printf("%c", r)
+			fmt.Print(string(displayRune(r)))
 		}
 	} else {
-		fmt.Printf("%c", r)
+		fmt.Print(string(displayRune(r)))
 	}
 
 	if insert {
@@ -267,7 +275,7 @@ func (b *Buffer) countRemainingLineWidth(place int) int {
 		sum += prevLen
 		if e, ok := b.Buf.Get(b.Pos + counter); ok {
 			if r, ok := e.(rune); ok {
-				place += runewidth.RuneWidth(r)
+				place += runeWidth(r)
 				prevLen = len(string(r))
 			}
 		} else {
@@ -290,11 +298,11 @@ func (b *Buffer) drawRemaining() {
 	currLineLength := b.countRemainingLineWidth(place)
 
 	currLine := remainingText[:min(currLineLength, len(remainingText))]
-	currLineSpace := runewidth.StringWidth(currLine)
-	remLength := runewidth.StringWidth(remainingText)
+	currLineSpace := stringWidth(currLine)
+	remLength := stringWidth(remainingText)
 
 	if len(currLine) > 0 {
-		fmt.Print(ClearToEOL + currLine + CursorLeftN(currLineSpace))
+		fmt.Print(ClearToEOL + displayString(currLine) + CursorLeftN(currLineSpace))
 	} else {
 		fmt.Print(ClearToEOL)
 	}
@@ -323,7 +331,7 @@ func (b *Buffer) drawRemaining() {
 
 		for _, c := range remaining {
 			if displayLength == 0 ||
-				(displayLength+runewidth.RuneWidth(c))%b.LineWidth < displayLength%b.LineWidth {
+				(displayLength+runeWidth(c))%b.LineWidth < displayLength%b.LineWidth {
 				fmt.Printf("\n%s", b.Prompt.AltPrompt)
 				totalLines += 1
 
@@ -341,9 +349,9 @@ func (b *Buffer) drawRemaining() {
 				lineLength = 0
 			}
 
-			displayLength += runewidth.RuneWidth(c)
-			lineLength += runewidth.RuneWidth(c)
-			fmt.Printf("%c", c)
+			displayLength += runeWidth(c)
+			lineLength += runeWidth(c)
+			fmt.Print(string(displayRune(c)))
 		}
 		fmt.Print(
 			ClearToEOL + CursorUpN(
@@ -367,7 +375,7 @@ func (b *Buffer) Remove() {
 	if b.Buf.Size() > 0 && b.Pos > 0 {
 		if e, ok := b.Buf.Get(b.Pos - 1); ok {
 			if r, ok := e.(rune); ok {
-				rLength := runewidth.RuneWidth(r)
+				rLength := runeWidth(r)
 				hasSpace := b.GetLineSpacing(b.DisplayPos/b.LineWidth - 1)
 
 				if b.DisplayPos%b.LineWidth == 0 {
@@ -542,7 +550,13 @@ func (b *Buffer) IsEmpty() bool {
 	return b.Buf.Empty()
 }
 
+// Replace replaces the line, as an edit that can be undone.
 func (b *Buffer) Replace(r []rune) {
+	b.checkpoint(editOther)
+	b.replace(r)
+}
+
+func (b *Buffer) replace(r []rune) {
 	b.DisplayPos = 0
 	b.Pos = 0
 	lineNums := b.DisplaySize() / b.LineWidth
@@ -562,6 +576,31 @@ func (b *Buffer) Replace(r []rune) {
 	}
 }
 
+// RedrawPrompt prints the prompt again, keeping the cursor in place.
+func (b *Buffer) RedrawPrompt() {
+	fmt.Print(CursorSave)
+	if line := b.DisplayPos / b.LineWidth; line > 0 {
+		fmt.Print(CursorUpN(line))
+	}
+	fmt.Print(CursorBOL + b.Prompt.prompt() + CursorRestore)
+}
+
+// Clear erases the lines of the buffer and its prompt, leaving the cursor
+// at the start of the first line.
+func (b *Buffer) Clear() {
+	b.MoveToEnd()
+	lineNums := b.DisplaySize() / b.LineWidth
+
+	fmt.Print(CursorBOL + ClearToEOL)
+	for range lineNums {
+		fmt.Print(CursorUp + CursorBOL + ClearToEOL)
+	}
+
+	b.Buf.Clear()
+	b.Pos = 0
+	b.DisplayPos = 0
+}
+
 func (b *Buffer) String() string {
 	return b.StringN(0)
 }
@@ -581,3 +620,34 @@ func (b *Buffer) StringNM(n, m int) string {
 	}
 	return s
 }
+
+// runeWidth is the width of a rune on the screen. Tabs, kept in pasted
+// text, are drawn as a single space.
+func runeWidth(r rune) int {
+	if r == '\t' {
+		return 1
+	}
+
+	return runewidth.RuneWidth(r)
+}
+
+func stringWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+
+	return width
+}
+
+func displayRune(r rune) rune {
+	if r == '\t' {
+		return ' '
+	}
+
+	return r
+}
+
+func displayString(s string) string {
+	return strings.ReplaceAll(s, "\t", " ")
+}
@@ -0,0 +1,141 @@
+package term
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// CommandPrefix starts the slash commands typed at the prompt.
+const CommandPrefix = "/"
+
+// Command is a slash command handled locally instead of being sent to the
+// model.
+type Command struct {
+	// Name is the command without its slash, like help.
+	Name string
+	// Usage describes the arguments, like <file>.
+	Usage       string
+	Description string
+	// Run handles the command. A non-empty result is sent to the model as
+	// if the user had typed it.
+	Run func(ctx context.Context, args string) (string, error)
+	// Complete, when set, returns the candidates of the argument being
+	// typed.
+	Complete func(word string) []string
+}
+
+// Commands is a registry of slash commands.
+type Commands struct {
+	commands map[string]Command
+}
+
+// NewCommands returns a registry of the commands.
+func NewCommands(commands ...Command) *Commands {
+	c := &Commands{
+		commands: make(map[string]Command, len(commands)),
+	}
+	for _, cmd := range commands {
+		c.Register(cmd)
+	}
+
+	return c
+}
+
+// Register adds a command, replacing any command of the same name.
+func (c *Commands) Register(cmd Command) {
+	c.commands[cmd.Name] = cmd
+}
+
+// List returns the commands sorted by name.
+func (c *Commands) List() []Command {
+	list := make([]Command, 0, len(c.commands))
+	for _, cmd := range c.commands {
+		list = append(list, cmd)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list
+}
+
+// Parse splits a line into a command name and its arguments. Lines that do
+// not start with a slash followed by a word, like paths, are not commands.
+func Parse(line string) (string, string, bool) {
+	line = strings.TrimSpace(line)
+	word, args, _ := strings.Cut(line, " ")
+	name, ok := strings.CutPrefix(word, CommandPrefix)
+	if !ok || name == "" || strings.ContainsAny(name, `/\.`) {
+		return "", "", false
+	}
+
+	return name, strings.TrimSpace(args), true
+}
+
+// Lookup returns the command of a line and its arguments. It reports
+// false when the line is not a command, and returns an error for unknown
+// ones.
+func (c *Commands) Lookup(line string) (Command, string, bool, error) {
+	name, args, ok := Parse(line)
+	if !ok {
+		return Command{}, "", false, nil
+	}
+
+	cmd, found := c.commands[name]
+	if !found {
+		return Command{}, "", true, fmt.Errorf(
+			"unknown command %s%s, type %shelp for the list of commands",
+			CommandPrefix,
+			name,
+			CommandPrefix,
+		)
+	}
+
+	return cmd, args, true, nil
+}
+
+// Complete returns the commands starting like the word while the name of
+// the command is being typed, and the candidates of the command for its
+// arguments.
+func (c *Commands) Complete(line, word string) []string {
+	if line != word {
+		name, _, ok := Parse(line)
+		if cmd, found := c.commands[name]; ok && found && cmd.Complete != nil {
+			return cmd.Complete(word)
+		}
+		return nil
+	}
+
+	name, ok := strings.CutPrefix(word, CommandPrefix)
+	if !ok {
+		return nil
+	}
+
+	var candidates []string
+	for _, cmd := range c.List() {
+		if strings.HasPrefix(cmd.Name, name) {
+			candidates = append(candidates, CommandPrefix+cmd.Name)
+		}
+	}
+
+	return candidates
+}
+
+// Help describes every command.
+func (c *Commands) Help() string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	for _, cmd := range c.List() {
+		usage := CommandPrefix + cmd.Name
+		if cmd.Usage != "" {
+			usage += " " + cmd.Usage
+		}
+		fmt.Fprintf(w, "  %s\t%s\n", usage, cmd.Description)
+	}
+	w.Flush()
+
+	return sb.String()
+}
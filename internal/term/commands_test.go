@@ -0,0 +1,120 @@
+package term
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func noop(context.Context, string) (string, error) {
+	return "", nil
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		line     string
+		command  string
+		args     string
+		expected bool
+	}{
+		{name: "Command", line: "/help", command: "help", expected: true},
+		{name: "Arguments", line: " /save  chat.json ", command: "save", args: "chat.json", expected: true},
+		{name: "Message", line: "list the files", expected: false},
+		{name: "Path", line: "/etc/hosts is broken", expected: false},
+		{name: "Slash only", line: "/", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			command, args, ok := Parse(tt.line)
+			if ok != tt.expected {
+				t.Fatalf("Expected %v, got %v", tt.expected, ok)
+			}
+			if command != tt.command || args != tt.args {
+				t.Errorf(
+					"Expected %q %q, got %q %q",
+					tt.command,
+					tt.args,
+					command,
+					args,
+				)
+			}
+		})
+	}
+}
+
+func TestCommandsLookup(t *testing.T) {
+	t.Parallel()
+
+	commands := NewCommands(Command{Name: "help", Run: noop})
+
+	cmd, args, ok, err := commands.Lookup("/help me")
+	if err != nil || !ok || cmd.Name != "help" || args != "me" {
+		t.Errorf("Unexpected lookup %q %q %v %v", cmd.Name, args, ok, err)
+	}
+
+	if _, _, ok, err := commands.Lookup("/unknown"); !ok || err == nil {
+		t.Errorf("Expected an error for unknown commands")
+	}
+
+	if _, _, ok, err := commands.Lookup("hello"); ok || err != nil {
+		t.Errorf("Expected messages not to be commands")
+	}
+}
+
+func TestCommandsComplete(t *testing.T) {
+	t.Parallel()
+
+	commands := NewCommands(
+		Command{Name: "reset", Run: noop},
+		Command{Name: "retry", Run: noop},
+		Command{Name: "help", Run: noop},
+		Command{
+			Name: "model",
+			Run:  noop,
+			Complete: func(word string) []string {
+				return []string{"llama3:" + word}
+			},
+		},
+	)
+
+	tests := []struct {
+		line     string
+		expected []string
+	}{
+		{line: "/re", expected: []string{"/reset", "/retry"}},
+		{line: "/h", expected: []string{"/help"}},
+		{line: "/help ", expected: nil},
+		{line: "hello", expected: nil},
+		{line: "/model 8b", expected: []string{"llama3:8b"}},
+	}
+
+	for _, tt := range tests {
+		got := commands.Complete(tt.line, lastWord(tt.line))
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Complete(%q): expected %v, got %v", tt.line, tt.expected, got)
+		}
+	}
+}
+
+func TestCommandsHelp(t *testing.T) {
+	t.Parallel()
+
+	help := NewCommands(
+		Command{Name: "save", Usage: "<file>", Description: "Save", Run: noop},
+		Command{Name: "exit", Description: "Exit", Run: noop},
+	).Help()
+
+	if !strings.Contains(help, "/save <file>") {
+		t.Errorf("Expected the usage in the help, got %q", help)
+	}
+	if strings.Index(help, "/exit") > strings.Index(help, "/save") {
+		t.Errorf("Expected commands to be sorted, got %q", help)
+	}
+}
@@ -0,0 +1,302 @@
+package term
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	// maxMenuRows is the height of the completion menu, longer lists
+	// being shown a page at a time.
+	maxMenuRows = 8
+	// historyWordTrim is trimmed around the words of the history.
+	historyWordTrim = `.,;:!?"'()[]{}<>`
+)
+
+// Completer returns the candidates replacing the word before the cursor.
+// The line is the text before the cursor and word its last word.
+type Completer func(line, word string) []string
+
+// Completers returns a completer asking each completer in turn, until one
+// has candidates.
+func Completers(completers ...Completer) Completer {
+	return func(line, word string) []string {
+		for _, complete := range completers {
+			if candidates := complete(line, word); len(candidates) > 0 {
+				return candidates
+			}
+		}
+
+		return nil
+	}
+}
+
+// NewFileCompleter returns a completer of paths, relative paths being
+// resolved against the directory returned by dir. Directories end with a
+// slash so their content can be completed next.
+func NewFileCompleter(dir func() string) Completer {
+	return func(line, word string) []string {
+		// The first word is the name of a command rather than a path.
+		if line == word {
+			if _, _, ok := Parse(word); ok {
+				return nil
+			}
+		}
+
+		prefix, base := "", word
+		if i := strings.LastIndexAny(word, `/\`); i >= 0 {
+			prefix, base = word[:i+1], word[i+1:]
+		}
+
+		path := prefix
+		if rest, ok := strings.CutPrefix(prefix, "~/"); ok {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil
+			}
+			path = filepath.Join(home, rest)
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir(), path)
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil
+		}
+
+		var candidates []string
+		for _, e := range entries {
+			name := e.Name()
+			if !strings.HasPrefix(name, base) {
+				continue
+			}
+			// Hidden files are only completed when asked for.
+			if strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".") {
+				continue
+			}
+
+			if isDir(filepath.Join(path, name), e) {
+				name += "/"
+			}
+			candidates = append(candidates, prefix+name)
+		}
+
+		return candidates
+	}
+}
+
+// isDir reports whether an entry is a directory, following symbolic
+// links.
+func isDir(path string, e os.DirEntry) bool {
+	if e.Type()&os.ModeSymlink == 0 {
+		return e.IsDir()
+	}
+
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// historyWords returns the words of the history starting like the word,
+// the most recent first.
+func historyWords(history *History, word string) []string {
+	if history == nil || word == "" {
+		return nil
+	}
+
+	seen := map[string]bool{word: true}
+	var candidates []string
+	lines := history.Lines()
+	for i := len(lines) - 1; i >= 0; i-- {
+		for _, w := range strings.Fields(lines[i]) {
+			w = strings.Trim(w, historyWordTrim)
+			if seen[w] || !strings.HasPrefix(w, word) {
+				continue
+			}
+			seen[w] = true
+			candidates = append(candidates, w)
+		}
+	}
+
+	return candidates
+}
+
+// lastWord returns the word at the end of the line.
+func lastWord(line string) string {
+	i := strings.LastIndexFunc(line, unicode.IsSpace)
+	if i < 0 {
+		return line
+	}
+
+	_, size := utf8.DecodeRuneInString(line[i:])
+	return line[i+size:]
+}
+
+func commonPrefix(candidates []string) string {
+	prefix := []rune(candidates[0])
+	for _, c := range candidates[1:] {
+		for !strings.HasPrefix(c, string(prefix)) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	return string(prefix)
+}
+
+// replaceWord replaces the n runes before the cursor by the text.
+func (b *Buffer) replaceWord(n int, text string) {
+	for range n {
+		b.Remove()
+	}
+	for _, r := range text {
+		b.Add(r)
+	}
+}
+
+// completionMenu lists the candidates of an ambiguous completion under the
+// line. Tab and Shift+Tab cycle through them, putting the selected one in
+// place of the word.
+type completionMenu struct {
+	// word is the word being completed, restored on cancel.
+	word       string
+	candidates []string
+	// selected is the index of the selected candidate, or -1.
+	selected int
+	// current is the text in place of the word.
+	current string
+}
+
+func newCompletionMenu(word string, candidates []string) *completionMenu {
+	return &completionMenu{
+		word:       word,
+		candidates: candidates,
+		selected:   -1,
+		current:    word,
+	}
+}
+
+// move selects the next candidate, or the previous one when delta is
+// negative.
+func (m *completionMenu) move(buf *Buffer, delta int) {
+	n := len(m.candidates)
+	if m.selected < 0 {
+		// Cycling is undone in one step, back to the word.
+		buf.checkpoint(editOther)
+		if delta < 0 {
+			m.selected = n
+		}
+	}
+	m.selected = ((m.selected+delta)%n + n) % n
+
+	m.set(buf, m.candidates[m.selected])
+	m.show(buf)
+}
+
+// cancel puts the word back and closes the menu.
+func (m *completionMenu) cancel(buf *Buffer) {
+	m.set(buf, m.word)
+	m.close(buf)
+}
+
+func (m *completionMenu) set(buf *Buffer, text string) {
+	buf.replaceWord(len([]rune(m.current)), text)
+	m.current = text
+}
+
+// show draws the menu under the line, leaving the cursor in place.
+func (m *completionMenu) show(buf *Buffer) {
+	lines := m.render(buf.Width)
+	pos := buf.Pos
+	buf.MoveToEnd()
+
+	// Making room first scrolls the screen when the line is at the bottom,
+	// before the position of the cursor is saved.
+	fmt.Print(
+		ClearToEOS + strings.Repeat("\r\n", len(lines)) +
+			CursorUpN(len(lines)) + CursorBOL,
+	)
+	if col := len(buf.Prompt.prompt()) + buf.DisplayPos%buf.LineWidth; col > 0 {
+		fmt.Print(CursorRightN(col))
+	}
+	fmt.Print(CursorSave)
+	for _, line := range lines {
+		fmt.Print("\r\n" + line)
+	}
+	fmt.Print(CursorRestore)
+
+	buf.moveTo(pos)
+}
+
+// close erases the menu.
+func (m *completionMenu) close(buf *Buffer) {
+	pos := buf.Pos
+	buf.MoveToEnd()
+	fmt.Print(ClearToEOS)
+	buf.moveTo(pos)
+}
+
+// render lays the candidates out in columns, showing the page of the
+// selected one.
+func (m *completionMenu) render(width int) []string {
+	labels := make([]string, len(m.candidates))
+	labelWidth := 0
+	for i, c := range m.candidates {
+		labels[i] = runewidth.Truncate(m.label(c), width-1, "…")
+		labelWidth = max(labelWidth, runewidth.StringWidth(labels[i]))
+	}
+
+	cols := max(1, (width-1)/(labelWidth+2))
+	pageSize := cols * maxMenuRows
+	start := max(m.selected, 0) / pageSize * pageSize
+	end := min(start+pageSize, len(labels))
+
+	var lines []string
+	var sb strings.Builder
+	for i := start; i < end; i++ {
+		padding := strings.Repeat(
+			" ",
+			labelWidth-runewidth.StringWidth(labels[i])+2,
+		)
+		label := labels[i]
+		if i == m.selected {
+			label = ColorReverse + label + ColorDefault
+		}
+		sb.WriteString(label + padding)
+
+		if (i-start+1)%cols == 0 || i == end-1 {
+			lines = append(lines, strings.TrimRight(sb.String(), " "))
+			sb.Reset()
+		}
+	}
+
+	if len(labels) > pageSize {
+		lines = append(lines, fmt.Sprintf(
+			"%s%d-%d of %d%s",
+			ColorGrey,
+			start+1,
+			end,
+			len(labels),
+			ColorDefault,
+		))
+	}
+
+	return lines
+}
+
+// label is how a candidate is listed: paths without their directory,
+// which the word already has.
+func (m *completionMenu) label(candidate string) string {
+	if i := strings.LastIndexAny(m.word, `/\`); i >= 0 {
+		if rest, ok := strings.CutPrefix(candidate, m.word[:i+1]); ok {
+			return rest
+		}
+	}
+
+	return candidate
+}
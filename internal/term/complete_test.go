@@ -0,0 +1,164 @@
+package term
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommonPrefix(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		candidates []string
+		expected   string
+	}{
+		{candidates: []string{"/reset", "/retry"}, expected: "/re"},
+		{candidates: []string{"été", "étage"}, expected: "ét"},
+		{candidates: []string{"abc"}, expected: "abc"},
+	}
+
+	for _, tt := range tests {
+		if got := commonPrefix(tt.candidates); got != tt.expected {
+			t.Errorf(
+				"commonPrefix(%v): expected %q, got %q",
+				tt.candidates,
+				tt.expected,
+				got,
+			)
+		}
+	}
+}
+
+func TestLastWord(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		line     string
+		expected string
+	}{
+		{line: "", expected: ""},
+		{line: "cat", expected: "cat"},
+		{line: "cat ~/Doc", expected: "~/Doc"},
+		{line: "cat ", expected: ""},
+		{line: "a\tb", expected: "b"},
+	}
+
+	for _, tt := range tests {
+		if got := lastWord(tt.line); got != tt.expected {
+			t.Errorf("lastWord(%q): expected %q, got %q", tt.line, tt.expected, got)
+		}
+	}
+}
+
+func TestFileCompleter(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"notes.md", "nomi.toml", ".hidden"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "src"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "src", "main.go"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	complete := NewFileCompleter(func() string { return dir })
+
+	tests := []struct {
+		line     string
+		expected []string
+	}{
+		{line: "cat no", expected: []string{"nomi.toml", "notes.md"}},
+		{line: "cat s", expected: []string{"src/"}},
+		{line: "cat src/m", expected: []string{"src/main.go"}},
+		{line: "cat .h", expected: []string{".hidden"}},
+		{line: "cat x", expected: nil},
+		{line: "/he", expected: nil},
+		{line: "cat " + dir + "/src/", expected: []string{dir + "/src/main.go"}},
+	}
+
+	for _, tt := range tests {
+		got := complete(tt.line, lastWord(tt.line))
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("complete(%q): expected %v, got %v", tt.line, tt.expected, got)
+		}
+	}
+}
+
+func TestCompleters(t *testing.T) {
+	t.Parallel()
+
+	none := func(string, string) []string { return nil }
+	some := func(_, word string) []string { return []string{word + "!"} }
+	never := func(string, string) []string {
+		t.Error("Expected the completers to stop at the first candidates")
+		return nil
+	}
+
+	got := Completers(none, some, never)("hi", "hi")
+	if !reflect.DeepEqual(got, []string{"hi!"}) {
+		t.Errorf("Expected [hi!], got %v", got)
+	}
+}
+
+func TestHistoryWords(t *testing.T) {
+	t.Parallel()
+
+	history, err := NewHistory("", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"list the files in docs",
+		"show disk usage of documents, then delete (docker) images",
+	} {
+		if err := history.Add([]rune(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := historyWords(history, "do")
+	expected := []string{"documents", "docker", "docs"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	if got := historyWords(history, ""); got != nil {
+		t.Errorf("Expected no candidates for an empty word, got %v", got)
+	}
+}
+
+func TestCompletionMenuRender(t *testing.T) {
+	t.Parallel()
+
+	candidates := make([]string, 0, 30)
+	for _, c := range "abcdefghijklmnopqrstuvwxyz0123" {
+		candidates = append(candidates, "src/"+string(c)+".go")
+	}
+	menu := newCompletionMenu("src/", candidates)
+
+	// Each label takes 4 columns and 2 of spacing, 3 fit in 20 columns.
+	lines := menu.render(20)
+	if len(lines) != maxMenuRows+1 {
+		t.Fatalf("Expected %d lines, got %d: %q", maxMenuRows+1, len(lines), lines)
+	}
+	if lines[0] != "a.go  b.go  c.go" {
+		t.Errorf("Expected labels without their directory, got %q", lines[0])
+	}
+	if !strings.Contains(lines[maxMenuRows], "1-24 of 30") {
+		t.Errorf("Expected the page in the footer, got %q", lines[maxMenuRows])
+	}
+
+	menu.selected = 25
+	lines = menu.render(20)
+	if !strings.Contains(lines[0], ColorReverse+"z.go") {
+		t.Errorf("Expected the selected candidate on the second page, got %q", lines[0])
+	}
+}
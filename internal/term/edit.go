@@ -0,0 +1,278 @@
+package term
+
+import (
+	"unicode"
+)
+
+// killRingSize is the number of killed texts remembered for yanking.
+const killRingSize = 32
+
+// KillRing remembers the texts killed from the line so they can be
+// yanked back, like in Emacs. It is shared by the lines of an instance.
+type KillRing struct {
+	entries []string
+	// pos is the entry yanked last.
+	pos int
+}
+
+func NewKillRing() *KillRing {
+	return &KillRing{}
+}
+
+// Push adds a killed text, dropping the oldest one above the size of the
+// ring.
+func (k *KillRing) Push(text string) {
+	if text == "" {
+		return
+	}
+
+	k.entries = append(k.entries, text)
+	if len(k.entries) > killRingSize {
+		k.entries = k.entries[len(k.entries)-killRingSize:]
+	}
+	k.pos = len(k.entries) - 1
+}
+
+// Top returns the last killed text.
+func (k *KillRing) Top() string {
+	if len(k.entries) == 0 {
+		return ""
+	}
+
+	k.pos = len(k.entries) - 1
+	return k.entries[k.pos]
+}
+
+// Rotate returns the text killed before the one yanked last.
+func (k *KillRing) Rotate() string {
+	if len(k.entries) == 0 {
+		return ""
+	}
+
+	k.pos--
+	if k.pos < 0 {
+		k.pos = len(k.entries) - 1
+	}
+
+	return k.entries[k.pos]
+}
+
+// editKind groups consecutive edits of the same kind in one undo step.
+type editKind int
+
+const (
+	editNone editKind = iota
+	editInsert
+	editDelete
+	editOther
+)
+
+// bufferState is the line and cursor before an edit.
+type bufferState struct {
+	line []rune
+	pos  int
+}
+
+// checkpoint saves the line before an edit, once for a run of insertions
+// or deletions.
+func (b *Buffer) checkpoint(kind editKind) {
+	if kind != editOther && kind == b.lastEdit {
+		return
+	}
+
+	b.lastEdit = kind
+	b.undo = append(b.undo, bufferState{line: b.runes(), pos: b.Pos})
+}
+
+// Undo restores the line as it was before the last edit.
+func (b *Buffer) Undo() {
+	if len(b.undo) == 0 {
+		return
+	}
+
+	state := b.undo[len(b.undo)-1]
+	b.undo = b.undo[:len(b.undo)-1]
+	b.setLine(state.line, state.pos)
+	b.lastEdit = editNone
+}
+
+// Insert types a rune at the cursor.
+func (b *Buffer) Insert(r rune) {
+	b.checkpoint(editInsert)
+	b.Add(r)
+}
+
+// Backspace removes the rune before the cursor.
+func (b *Buffer) Backspace() {
+	b.checkpoint(editDelete)
+	b.Remove()
+}
+
+// DeleteChar removes the rune under the cursor.
+func (b *Buffer) DeleteChar() {
+	b.checkpoint(editDelete)
+	b.Delete()
+}
+
+// KillRemaining kills the text from the cursor to the end of the line.
+func (b *Buffer) KillRemaining() {
+	b.checkpoint(editOther)
+	b.kill(string(b.runes()[b.Pos:]))
+	b.DeleteRemaining()
+}
+
+// KillBefore kills the text from the start of the line to the cursor.
+func (b *Buffer) KillBefore() {
+	b.checkpoint(editOther)
+	b.kill(string(b.runes()[:b.Pos]))
+	b.DeleteBefore()
+}
+
+// KillWord kills the word before the cursor.
+func (b *Buffer) KillWord() {
+	b.checkpoint(editOther)
+	line, end := b.runes(), b.Pos
+	b.DeleteWord()
+	b.kill(string(line[b.Pos:end]))
+}
+
+// KillWordForward kills the text from the cursor to the end of the word.
+func (b *Buffer) KillWordForward() {
+	b.checkpoint(editOther)
+	line := b.runes()
+	end := wordEnd(line, b.Pos)
+	b.kill(string(line[b.Pos:end]))
+	for range end - b.Pos {
+		b.Delete()
+	}
+}
+
+func (b *Buffer) kill(text string) {
+	if b.KillRing != nil {
+		b.KillRing.Push(text)
+	}
+}
+
+// Yank inserts the last killed text at the cursor.
+func (b *Buffer) Yank() {
+	if b.KillRing == nil {
+		return
+	}
+
+	b.checkpoint(editOther)
+	b.insertYank(b.KillRing.Top())
+}
+
+// YankPop replaces the text just yanked by the text killed before it. It
+// must directly follow a yank.
+func (b *Buffer) YankPop() {
+	if b.KillRing == nil || b.yanked == 0 {
+		return
+	}
+
+	b.checkpoint(editOther)
+	for range b.yanked {
+		b.Remove()
+	}
+	b.insertYank(b.KillRing.Rotate())
+}
+
+func (b *Buffer) insertYank(text string) {
+	b.yanked = 0
+	for _, r := range text {
+		b.Add(r)
+		b.yanked++
+	}
+}
+
+// Transpose swaps the rune before the cursor with the rune under it and
+// moves forward. At the end of the line, the last two runes are swapped.
+func (b *Buffer) Transpose() {
+	line, pos := b.runes(), b.Pos
+	if len(line) < 2 || pos == 0 {
+		return
+	}
+	if pos == len(line) {
+		pos--
+	}
+
+	b.checkpoint(editOther)
+	line[pos-1], line[pos] = line[pos], line[pos-1]
+	b.setLine(line, pos+1)
+}
+
+// UpcaseWord upcases the text from the cursor to the end of the word.
+func (b *Buffer) UpcaseWord() {
+	b.caseWord(func(_ int, r rune) rune {
+		return unicode.ToUpper(r)
+	})
+}
+
+// DowncaseWord downcases the text from the cursor to the end of the word.
+func (b *Buffer) DowncaseWord() {
+	b.caseWord(func(_ int, r rune) rune {
+		return unicode.ToLower(r)
+	})
+}
+
+// CapitalizeWord upcases the first letter of the next word and downcases
+// the rest of it.
+func (b *Buffer) CapitalizeWord() {
+	b.caseWord(func(i int, r rune) rune {
+		if i == 0 {
+			return unicode.ToUpper(r)
+		}
+		return unicode.ToLower(r)
+	})
+}
+
+// caseWord maps the runes of the next word, counted from its first
+// letter, and moves after it.
+func (b *Buffer) caseWord(mapping func(i int, r rune) rune) {
+	line := b.runes()
+	start := b.Pos
+	for start < len(line) && unicode.IsSpace(line[start]) {
+		start++
+	}
+	end := wordEnd(line, b.Pos)
+	if start == end {
+		return
+	}
+
+	b.checkpoint(editOther)
+	for i := start; i < end; i++ {
+		line[i] = mapping(i-start, line[i])
+	}
+	b.setLine(line, end)
+}
+
+// wordEnd returns the end of the word at or after pos.
+func wordEnd(line []rune, pos int) int {
+	for pos < len(line) && unicode.IsSpace(line[pos]) {
+		pos++
+	}
+	for pos < len(line) && !unicode.IsSpace(line[pos]) {
+		pos++
+	}
+
+	return pos
+}
+
+// setLine redraws the buffer with a new line and moves the cursor to pos.
+func (b *Buffer) setLine(line []rune, pos int) {
+	b.replace(line)
+	for b.Pos > pos {
+		b.MoveLeft()
+	}
+}
+
+func (b *Buffer) runes() []rune {
+	line := make([]rune, 0, b.Buf.Size())
+	for _, e := range b.Buf.Values() {
+		if r, ok := e.(rune); ok {
+			line = append(line, r)
+		}
+	}
+
+	return line
+}
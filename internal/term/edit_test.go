@@ -0,0 +1,143 @@
+package term
+
+import "testing"
+
+func newTestBuffer(t *testing.T, line string) *Buffer {
+	t.Helper()
+
+	buf, err := NewBuffer(&Prompt{Prompt: ">>> "})
+	if err != nil {
+		t.Fatalf("Failed to create buffer: %v", err)
+	}
+	buf.KillRing = NewKillRing()
+	for _, r := range line {
+		buf.Insert(r)
+	}
+
+	return buf
+}
+
+func TestBufferEdits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		line     string
+		edit     func(b *Buffer)
+		expected string
+	}{
+		{
+			name: "Kill and yank",
+			line: "hello world",
+			edit: func(b *Buffer) {
+				b.MoveToStart()
+				b.KillWordForward()
+				b.MoveToEnd()
+				b.Insert(' ')
+				b.Yank()
+			},
+			expected: " world hello",
+		},
+		{
+			name: "Yank pop",
+			line: "one two",
+			edit: func(b *Buffer) {
+				b.KillWord()
+				b.KillWord()
+				b.Yank()
+				b.YankPop()
+			},
+			expected: "two",
+		},
+		{
+			name: "Kill before and remaining",
+			line: "abcdef",
+			edit: func(b *Buffer) {
+				b.MoveLeft()
+				b.MoveLeft()
+				b.KillRemaining()
+				b.MoveLeft()
+				b.KillBefore()
+				b.MoveToEnd()
+				b.Yank()
+			},
+			expected: "dabc",
+		},
+		{
+			name: "Transpose at end",
+			line: "ab",
+			edit: func(b *Buffer) {
+				b.Transpose()
+			},
+			expected: "ba",
+		},
+		{
+			name: "Transpose in the middle",
+			line: "abc",
+			edit: func(b *Buffer) {
+				b.MoveLeft()
+				b.Transpose()
+			},
+			expected: "acb",
+		},
+		{
+			name: "Case",
+			line: "foo bar baz",
+			edit: func(b *Buffer) {
+				b.MoveToStart()
+				b.UpcaseWord()
+				b.CapitalizeWord()
+				b.MoveToEnd()
+				b.MoveLeftWord()
+				b.UpcaseWord()
+				b.MoveLeftWord()
+				b.DowncaseWord()
+			},
+			expected: "FOO Bar baz",
+		},
+		{
+			name: "Undo",
+			line: "abc",
+			edit: func(b *Buffer) {
+				b.KillWord()
+				b.Insert('x')
+				b.Undo()
+				b.Undo()
+			},
+			expected: "abc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := newTestBuffer(t, tt.line)
+			tt.edit(b)
+			if got := b.String(); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestKillRing(t *testing.T) {
+	t.Parallel()
+
+	k := NewKillRing()
+	if got := k.Top(); got != "" {
+		t.Errorf("Expected an empty ring, got %q", got)
+	}
+
+	for i := range killRingSize + 2 {
+		k.Push(string(rune('a' + i%26)))
+	}
+	if len(k.entries) != killRingSize {
+		t.Errorf("Expected %d entries, got %d", killRingSize, len(k.entries))
+	}
+
+	top := k.Top()
+	if got := k.Rotate(); got == top {
+		t.Errorf("Expected rotate to move to an older entry")
+	}
+}
@@ -0,0 +1,269 @@
+package term
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	colorKeyword  = Esc + "[38;5;170m"
+	colorString   = Esc + "[38;5;114m"
+	colorNumber   = Esc + "[38;5;179m"
+	colorVariable = Esc + "[38;5;81m"
+	colorComment  = ColorGrey
+	// colorCode is the color of code spans in text.
+	colorCode = Esc + "[38;5;216m"
+)
+
+// syntax describes the tokens of a language, enough to color keywords,
+// strings, numbers and comments.
+type syntax struct {
+	keywords      []string
+	lineComments  []string
+	blockComments [2]string
+	quotes        string
+	// variables are introduced by a dollar, like in shell scripts, where
+	// comments only start at the beginning of a word.
+	variables bool
+}
+
+var (
+	syntaxGo = &syntax{
+		keywords: []string{
+			"break", "case", "chan", "const", "continue", "default", "defer",
+			"else", "fallthrough", "for", "func", "go", "goto", "if",
+			"import", "interface", "map", "package", "range", "return",
+			"select", "struct", "switch", "type", "var", "nil", "true",
+			"false",
+		},
+		lineComments:  []string{"//"},
+		blockComments: [2]string{"/*", "*/"},
+		quotes:        "\"'`",
+	}
+	syntaxPython = &syntax{
+		keywords: []string{
+			"and", "as", "assert", "async", "await", "break", "class",
+			"continue", "def", "del", "elif", "else", "except", "finally",
+			"for", "from", "global", "if", "import", "in", "is", "lambda",
+			"nonlocal", "not", "or", "pass", "raise", "return", "try",
+			"while", "with", "yield", "None", "True", "False", "self",
+		},
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	syntaxShell = &syntax{
+		keywords: []string{
+			"if", "then", "else", "elif", "fi", "for", "while", "until",
+			"do", "done", "case", "esac", "in", "function", "return",
+			"local", "export", "set", "unset", "readonly", "shift", "exit",
+			"echo", "cd", "source", "sudo",
+		},
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+		variables:    true,
+	}
+	syntaxJavaScript = &syntax{
+		keywords: []string{
+			"async", "await", "break", "case", "catch", "class", "const",
+			"continue", "default", "delete", "do", "else", "export",
+			"extends", "finally", "for", "function", "if", "import", "in",
+			"instanceof", "let", "new", "of", "return", "switch", "this",
+			"throw", "try", "typeof", "var", "void", "while", "yield",
+			"null", "undefined", "true", "false", "interface", "type",
+		},
+		lineComments:  []string{"//"},
+		blockComments: [2]string{"/*", "*/"},
+		quotes:        "\"'`",
+	}
+	syntaxRuby = &syntax{
+		keywords: []string{
+			"begin", "class", "def", "do", "else", "elsif", "end", "ensure",
+			"for", "if", "in", "module", "next", "nil", "puts", "raise",
+			"require", "rescue", "return", "self", "then", "true", "false",
+			"unless", "until", "when", "while", "yield",
+		},
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	syntaxSQL = &syntax{
+		keywords: []string{
+			"select", "from", "where", "and", "or", "not", "insert", "into",
+			"values", "update", "set", "delete", "create", "table", "drop",
+			"alter", "join", "left", "right", "inner", "outer", "on", "as",
+			"group", "by", "order", "having", "limit", "null", "is", "in",
+			"distinct", "count", "primary", "key",
+		},
+		lineComments:  []string{"--"},
+		blockComments: [2]string{"/*", "*/"},
+		quotes:        "'\"",
+	}
+	syntaxJSON = &syntax{
+		keywords: []string{"true", "false", "null"},
+		quotes:   "\"",
+	}
+	syntaxYAML = &syntax{
+		keywords:     []string{"true", "false", "null", "yes", "no"},
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+)
+
+// syntaxes are the languages of fenced code, by the names used for them.
+var syntaxes = map[string]*syntax{
+	"go":         syntaxGo,
+	"golang":     syntaxGo,
+	"python":     syntaxPython,
+	"python3":    syntaxPython,
+	"py":         syntaxPython,
+	"bash":       syntaxShell,
+	"sh":         syntaxShell,
+	"shell":      syntaxShell,
+	"zsh":        syntaxShell,
+	"console":    syntaxShell,
+	"javascript": syntaxJavaScript,
+	"js":         syntaxJavaScript,
+	"node":       syntaxJavaScript,
+	"typescript": syntaxJavaScript,
+	"ts":         syntaxJavaScript,
+	"ruby":       syntaxRuby,
+	"rb":         syntaxRuby,
+	"sql":        syntaxSQL,
+	"json":       syntaxJSON,
+	"yaml":       syntaxYAML,
+	"yml":        syntaxYAML,
+	"toml":       syntaxYAML,
+}
+
+// highlighter colors the lines of a code block, remembering the block
+// comments left open at the end of a line.
+type highlighter struct {
+	syntax    *syntax
+	inComment bool
+}
+
+// newHighlighter returns a highlighter of the language, nil when the
+// language is unknown.
+func newHighlighter(language string) *highlighter {
+	s, ok := syntaxes[strings.ToLower(language)]
+	if !ok {
+		return nil
+	}
+
+	return &highlighter{syntax: s}
+}
+
+// line colors a line of code.
+func (h *highlighter) line(line string) string {
+	s := h.syntax
+	runes := []rune(line)
+
+	var sb strings.Builder
+	emit := func(color string, text []rune) {
+		sb.WriteString(color + string(text) + ColorDefault)
+	}
+
+	for i := 0; i < len(runes); {
+		rest := string(runes[i:])
+
+		if h.inComment {
+			end := strings.Index(rest, s.blockComments[1])
+			if end < 0 {
+				emit(colorComment, runes[i:])
+				break
+			}
+			n := len([]rune(rest[:end+len(s.blockComments[1])]))
+			emit(colorComment, runes[i:i+n])
+			i += n
+			h.inComment = false
+			continue
+		}
+
+		if s.blockComments[0] != "" && strings.HasPrefix(rest, s.blockComments[0]) {
+			h.inComment = true
+			n := len([]rune(s.blockComments[0]))
+			emit(colorComment, runes[i:i+n])
+			i += n
+			continue
+		}
+
+		if hasAnyPrefix(rest, s.lineComments) &&
+			(!s.variables || i == 0 || unicode.IsSpace(runes[i-1])) {
+			emit(colorComment, runes[i:])
+			break
+		}
+
+		r := runes[i]
+		switch {
+		case strings.ContainsRune(s.quotes, r):
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				if runes[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(runes))
+			emit(colorString, runes[i:end])
+			i = end
+		case s.variables && r == '$' && i+1 < len(runes):
+			end := i + 1
+			if runes[end] == '{' {
+				for end < len(runes) && runes[end] != '}' {
+					end++
+				}
+				end = min(end+1, len(runes))
+			} else {
+				for end < len(runes) && isWordRune(runes[end]) {
+					end++
+				}
+			}
+			emit(colorVariable, runes[i:end])
+			i = end
+		case isWordRune(r):
+			end := i
+			for end < len(runes) && isWordRune(runes[end]) {
+				end++
+			}
+			word := runes[i:end]
+			switch {
+			case unicode.IsDigit(r):
+				emit(colorNumber, word)
+			case s.isKeyword(string(word)):
+				emit(colorKeyword, word)
+			default:
+				sb.WriteString(string(word))
+			}
+			i = end
+		default:
+			sb.WriteRune(r)
+			i++
+		}
+	}
+
+	return sb.String()
+}
+
+func (s *syntax) isKeyword(word string) bool {
+	for _, k := range s.keywords {
+		// Keywords of SQL are written in any case.
+		if k == word || (s == syntaxSQL && strings.EqualFold(k, word)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
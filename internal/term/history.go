@@ -0,0 +1,207 @@
+package term
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxHistoryLineLength is the length above which lines, usually pasted
+// content, are not remembered.
+const maxHistoryLineLength = 4096
+
+// History remembers the lines typed at the prompt. When it has a file,
+// every line is saved to it so the history survives the session.
+type History struct {
+	lines []string
+	Pos   int
+	// Limit is the maximum number of lines remembered.
+	Limit   int
+	Enabled bool
+	path    string
+	// exclude rejects lines that must not be remembered.
+	exclude func(line string) bool
+}
+
+// NewHistory returns a history saved to path, or kept in memory when path
+// is empty, loading the lines already saved.
+func NewHistory(
+	path string,
+	limit int,
+	exclude func(line string) bool,
+) (*History, error) {
+	h := &History{
+		Limit:   limit,
+		Enabled: true,
+		path:    path,
+		exclude: exclude,
+	}
+
+	lines, err := h.load()
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range lines {
+		h.append(line)
+	}
+	h.Pos = h.Size()
+
+	return h, nil
+}
+
+// Add remembers a line and saves the history. Empty lines, lines starting
+// with a space, overly long lines and excluded lines are skipped; earlier
+// copies of the line are removed.
+func (h *History) Add(l []rune) error {
+	line := string(l)
+	defer func() {
+		h.Pos = h.Size()
+	}()
+
+	if !h.Enabled || !h.accepts(line) {
+		return nil
+	}
+
+	// Other sessions may have saved lines since the history was loaded.
+	if lines, err := h.load(); err == nil && lines != nil {
+		h.lines = nil
+		for _, saved := range lines {
+			h.append(saved)
+		}
+	}
+	h.append(line)
+
+	return h.save()
+}
+
+func (h *History) accepts(line string) bool {
+	switch {
+	case strings.TrimSpace(line) == "",
+		strings.HasPrefix(line, " "),
+		strings.ContainsAny(line, "\r\n"),
+		len(line) > maxHistoryLineLength:
+		return false
+	case h.exclude != nil && h.exclude(line):
+		return false
+	default:
+		return true
+	}
+}
+
+// append adds a line, removing its earlier copies and the oldest lines
+// above the limit.
+func (h *History) append(line string) {
+	for i, l := range h.lines {
+		if l == line {
+			h.lines = append(h.lines[:i], h.lines[i+1:]...)
+			break
+		}
+	}
+	h.lines = append(h.lines, line)
+
+	if h.Limit > 0 && len(h.lines) > h.Limit {
+		h.lines = h.lines[len(h.lines)-h.Limit:]
+	}
+}
+
+func (h *History) Prev() []rune {
+	if h.Pos > 0 {
+		h.Pos--
+	}
+
+	return h.line(h.Pos)
+}
+
+func (h *History) Next() []rune {
+	if h.Pos < h.Size() {
+		h.Pos++
+	}
+
+	return h.line(h.Pos)
+}
+
+func (h *History) line(i int) []rune {
+	if i < 0 || i >= h.Size() {
+		return nil
+	}
+
+	return []rune(h.lines[i])
+}
+
+func (h *History) Size() int {
+	return len(h.lines)
+}
+
+// Lines returns the remembered lines, oldest first.
+func (h *History) Lines() []string {
+	return h.lines
+}
+
+// load reads the saved lines. It returns nil when there is no file.
+func (h *History) load() ([]string, error) {
+	if h.path == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening history: %w", err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxHistoryLineLength+1)
+	for scanner.Scan() {
+		if line := scanner.Text(); h.accepts(line) {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading history: %w", err)
+	}
+
+	return lines, nil
+}
+
+// save writes the history to a temporary file renamed over the previous
+// one, so a concurrent reader never sees a partial history.
+func (h *History) save() error {
+	if h.path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o700); err != nil {
+		return fmt.Errorf("error creating history directory: %w", err)
+	}
+
+	tmp := h.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("error opening history: %w", err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	for _, line := range h.lines {
+		w.WriteString(line + "\n")
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("error writing history: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error writing history: %w", err)
+	}
+
+	if err := os.Rename(tmp, h.path); err != nil {
+		return fmt.Errorf("error saving history: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package term
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHistoryAdd(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "nomi", "history")
+	exclude := func(line string) bool {
+		return strings.Contains(line, "TOKEN=")
+	}
+
+	h, err := NewHistory(path, 3, exclude)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, line := range []string{
+		"first",
+		"second",
+		"first",
+		" private",
+		"export TOKEN=abc",
+		"",
+		"third",
+		"fourth",
+	} {
+		if err := h.Add([]rune(line)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	expected := []string{"first", "third", "fourth"}
+	if !reflect.DeepEqual(h.Lines(), expected) {
+		t.Errorf("Expected %v, got %v", expected, h.Lines())
+	}
+	if h.Pos != h.Size() {
+		t.Errorf("Expected position at the end, got %d", h.Pos)
+	}
+
+	reloaded, err := NewHistory(path, 3, exclude)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(reloaded.Lines(), expected) {
+		t.Errorf("Expected saved %v, got %v", expected, reloaded.Lines())
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Expected history file, got %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("Expected private history file, got %v", perm)
+	}
+}
+
+func TestHistoryMergesSessions(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "history")
+	a, _ := NewHistory(path, 10, nil)
+	b, _ := NewHistory(path, 10, nil)
+
+	a.Add([]rune("from a"))
+	b.Add([]rune("from b"))
+
+	expected := []string{"from a", "from b"}
+	if !reflect.DeepEqual(b.Lines(), expected) {
+		t.Errorf("Expected %v, got %v", expected, b.Lines())
+	}
+}
+
+func TestHistoryNavigation(t *testing.T) {
+	t.Parallel()
+
+	h, _ := NewHistory("", 0, nil)
+	h.Add([]rune("one"))
+	h.Add([]rune("two"))
+
+	if got := string(h.Prev()); got != "two" {
+		t.Errorf("Expected two, got %q", got)
+	}
+	if got := string(h.Prev()); got != "one" {
+		t.Errorf("Expected one, got %q", got)
+	}
+	if got := string(h.Prev()); got != "one" {
+		t.Errorf("Expected to stay on one, got %q", got)
+	}
+	if got := string(h.Next()); got != "two" {
+		t.Errorf("Expected two, got %q", got)
+	}
+	if got := h.Next(); got != nil {
+		t.Errorf("Expected the end of the history, got %q", string(got))
+	}
+}
@@ -0,0 +1,312 @@
+package term
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// span is a run of text sharing a style.
+type span struct {
+	text  string
+	style string
+}
+
+func (s span) String() string {
+	if s.style == "" || s.text == "" {
+		return s.text
+	}
+
+	return s.style + s.text + ColorDefault
+}
+
+// parseInline parses the emphasis, code, links and escapes of a text, on
+// top of a base style.
+func parseInline(text, style string) []span {
+	p := inlineParser{runes: []rune(text)}
+	p.parse(style)
+
+	return p.spans
+}
+
+type inlineParser struct {
+	runes []rune
+	pos   int
+	spans []span
+}
+
+func (p *inlineParser) add(text, style string) {
+	if text == "" {
+		return
+	}
+
+	// Runs of the same style are merged, emitting fewer escapes.
+	if n := len(p.spans); n > 0 && p.spans[n-1].style == style {
+		p.spans[n-1].text += text
+		return
+	}
+	p.spans = append(p.spans, span{text: text, style: style})
+}
+
+// parse reads the text, adding its spans.
+func (p *inlineParser) parse(style string) {
+	for p.pos < len(p.runes) {
+		r := p.runes[p.pos]
+		rest := string(p.runes[p.pos:])
+
+		switch {
+		case r == '\\' && p.pos+1 < len(p.runes) &&
+			unicode.IsPunct(p.runes[p.pos+1]):
+			p.add(string(p.runes[p.pos+1]), style)
+			p.pos += 2
+		case r == '`':
+			if !p.codeSpan(style) {
+				p.add("`", style)
+				p.pos++
+			}
+		case strings.HasPrefix(rest, "**") || strings.HasPrefix(rest, "__"):
+			if !p.emphasis(string(p.runes[p.pos:p.pos+2]), style+StyleBold) {
+				p.add(string(p.runes[p.pos:p.pos+2]), style)
+				p.pos += 2
+			}
+		case strings.HasPrefix(rest, "~~"):
+			if !p.emphasis("~~", style+StyleStrike) {
+				p.add("~~", style)
+				p.pos += 2
+			}
+		case r == '*' || r == '_':
+			if !p.emphasis(string(r), style+StyleItalic) {
+				p.add(string(r), style)
+				p.pos++
+			}
+		case r == '[' || (r == '!' && strings.HasPrefix(rest, "![")):
+			if !p.link(style) {
+				p.add(string(r), style)
+				p.pos++
+			}
+		case r == '<':
+			if !p.autolink(style) {
+				p.add("<", style)
+				p.pos++
+			}
+		default:
+			p.add(string(r), style)
+			p.pos++
+		}
+	}
+}
+
+// codeSpan reads text between backticks, which is not parsed.
+func (p *inlineParser) codeSpan(style string) bool {
+	n := 0
+	for p.pos+n < len(p.runes) && p.runes[p.pos+n] == '`' {
+		n++
+	}
+	marker := strings.Repeat("`", n)
+
+	rest := string(p.runes[p.pos+n:])
+	end := strings.Index(rest, marker)
+	if end < 0 {
+		return false
+	}
+
+	code := rest[:end]
+	p.add(strings.TrimSpace(code), style+colorCode)
+	p.pos += n + len([]rune(code)) + n
+	return true
+}
+
+// emphasis reads text between the delimiters, which must hug it. The
+// underscore does not emphasize inside words, like in snake_case.
+func (p *inlineParser) emphasis(delim, style string) bool {
+	n := len([]rune(delim))
+	start := p.pos + n
+	if start >= len(p.runes) || unicode.IsSpace(p.runes[start]) {
+		return false
+	}
+	if delim[0] == '_' && p.pos > 0 && isWordRune(p.runes[p.pos-1]) {
+		return false
+	}
+
+	end := p.findClosing(delim, start)
+	if end < 0 {
+		return false
+	}
+
+	inner := inlineParser{runes: p.runes[start:end]}
+	inner.parse(style)
+	for _, s := range inner.spans {
+		p.add(s.text, s.style)
+	}
+	p.pos = end + n
+	return true
+}
+
+// findClosing returns the position of the delimiter closing an emphasis
+// that starts at start, or -1.
+func (p *inlineParser) findClosing(delim string, start int) int {
+	n := len([]rune(delim))
+	for i := start; i+n <= len(p.runes); i++ {
+		if p.runes[i] == '`' {
+			// Delimiters in code spans do not count.
+			for i++; i < len(p.runes) && p.runes[i] != '`'; i++ {
+			}
+			continue
+		}
+		// Emphasis is not empty, and ends after a non-space.
+		if i == start || string(p.runes[i:i+n]) != delim ||
+			unicode.IsSpace(p.runes[i-1]) {
+			continue
+		}
+		// A single delimiter must not be the start of a double one.
+		if n == 1 && i+1 < len(p.runes) && p.runes[i+1] == p.runes[i] {
+			i++
+			continue
+		}
+		if delim[0] == '_' && i+n < len(p.runes) && isWordRune(p.runes[i+n]) {
+			continue
+		}
+
+		return i
+	}
+
+	return -1
+}
+
+// link reads [text](url) and ![alt](url), showing the address after the
+// text unless they are the same.
+func (p *inlineParser) link(style string) bool {
+	image := p.runes[p.pos] == '!'
+	start := p.pos + 1
+	if image {
+		start++
+	}
+
+	rest := string(p.runes[start:])
+	closeText := strings.Index(rest, "](")
+	if closeText < 0 {
+		return false
+	}
+	closeURL := strings.Index(rest[closeText+2:], ")")
+	if closeURL < 0 {
+		return false
+	}
+
+	text := rest[:closeText]
+	url := rest[closeText+2 : closeText+2+closeURL]
+	if image {
+		text = "image: " + text
+	}
+
+	inner := inlineParser{runes: []rune(text)}
+	inner.parse(style + StyleUnderline + ColorBlue)
+	for _, s := range inner.spans {
+		p.add(s.text, s.style)
+	}
+	if url != "" && url != text {
+		p.add(" ("+url+")", style+ColorGrey)
+	}
+
+	p.pos = start + len([]rune(rest[:closeText+2+closeURL+1]))
+	return true
+}
+
+// autolink reads <https://...>.
+func (p *inlineParser) autolink(style string) bool {
+	rest := string(p.runes[p.pos+1:])
+	end := strings.Index(rest, ">")
+	if end < 0 {
+		return false
+	}
+
+	url := rest[:end]
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return false
+	}
+
+	p.add(url, style+StyleUnderline+ColorBlue)
+	p.pos += 1 + len([]rune(url)) + 1
+	return true
+}
+
+// spansText is the text of spans, without their styles.
+func spansText(spans []span) string {
+	var sb strings.Builder
+	for _, s := range spans {
+		sb.WriteString(s.text)
+	}
+
+	return sb.String()
+}
+
+// renderSpans styles the spans, merging the runs of the same style.
+func renderSpans(spans []span) string {
+	var sb strings.Builder
+	for i := 0; i < len(spans); {
+		s := spans[i]
+		for i++; i < len(spans) && spans[i].style == s.style; i++ {
+			s.text += spans[i].text
+		}
+		sb.WriteString(s.String())
+	}
+
+	return sb.String()
+}
+
+// wrapSpans breaks styled text into lines of at most width columns, at
+// spaces. Words longer than a line are left whole.
+func wrapSpans(spans []span, width int) []string {
+	type word struct {
+		spans []span
+		width int
+	}
+
+	var words []word
+	var current word
+	endWord := func() {
+		if len(current.spans) > 0 {
+			words = append(words, current)
+		}
+		current = word{}
+	}
+	for _, s := range spans {
+		for i, part := range strings.Split(s.text, " ") {
+			if i > 0 {
+				endWord()
+			}
+			if part != "" {
+				current.spans = append(current.spans, span{text: part, style: s.style})
+				current.width += runewidth.StringWidth(part)
+			}
+		}
+	}
+	endWord()
+
+	var lines []string
+	var line []span
+	lineWidth := 0
+	for _, w := range words {
+		if lineWidth > 0 && lineWidth+1+w.width > width {
+			lines = append(lines, renderSpans(line))
+			line, lineWidth = nil, 0
+		}
+		if lineWidth > 0 {
+			// The space between words of the same style has it too, so
+			// that underlines are not broken.
+			space := span{text: " "}
+			if prev := line[len(line)-1]; prev.style == w.spans[0].style {
+				space.style = prev.style
+			}
+			line = append(line, space)
+			lineWidth++
+		}
+		line = append(line, w.spans...)
+		lineWidth += w.width
+	}
+	if len(line) > 0 || len(lines) == 0 {
+		lines = append(lines, renderSpans(line))
+	}
+
+	return lines
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/ollama/ollama/readline"
 )
@@ -13,19 +15,35 @@ import (
 type Instance struct {
 	Prompt   *Prompt
 	Terminal *Terminal
-	History  *readline.History
+	History  *History
 	Pasting  bool
+	// Completer, when set, completes the word before the cursor on tab,
+	// words of the history being completed otherwise.
+	Completer Completer
+	// killRing keeps the killed text across lines.
+	killRing *KillRing
+	EditMode EditMode
+	// Editor, when set, edits the line in an external editor on
+	// Ctrl+X Ctrl+E, or v in the normal mode of vi.
+	Editor Editor
+	// Secret reads the line without showing or remembering it, like a
+	// password.
+	Secret bool
 }
 
+// Editor edits a text in an external editor and returns the result.
+type Editor func(text string) (string, error)
+
 func (i *Instance) Readline() (string, error) { // nolint:gocyclo
-	if !i.Terminal.rawmode {
-		fd := os.Stdin.Fd()
-		termios, err := readline.SetRawMode(fd)
-		if err != nil {
-			return "", fmt.Errorf("failed to set raw mode: %w", err)
-		}
-		i.Terminal.rawmode = true
-		i.Terminal.termios = termios
+	if err := i.setRawMode(); err != nil {
+		return "", err
+	}
+
+	// v is the state of the vi mode, when enabled.
+	var v *vi
+	i.Prompt.Mode = ""
+	if i.EditMode == EditModeVi {
+		v = newVi(i.Prompt)
 	}
 
 	prompt := i.Prompt.prompt()
@@ -42,17 +60,46 @@ func (i *Instance) Readline() (string, error) { // nolint:gocyclo
 		i.Terminal.rawmode = false
 	}()
 
-	buf, _ := NewBuffer(i.Prompt)
+	if i.Secret {
+		return i.readSecret()
+	}
+
+	buf := i.newBuffer()
 
 	var esc bool
 	var escex bool
 	var metaDel bool
 
 	var currentLineBuf []rune
+	// search is the history search in progress, if any.
+	var search *historySearch
+	// yanking is set right after a yank, allowing Alt+Y to cycle.
+	var yanking bool
+	// ctrlX is set after Ctrl+X, the prefix of Ctrl+X Ctrl+E.
+	var ctrlX bool
+	// menu lists the candidates of an ambiguous completion, if any.
+	var menu *completionMenu
+
+	historyPrev := func() {
+		if i.History.Pos > 0 {
+			if i.History.Pos == i.History.Size() {
+				currentLineBuf = []rune(buf.String())
+			}
+			buf.Replace(i.History.Prev())
+		}
+	}
+	historyNext := func() {
+		if i.History.Pos < i.History.Size() {
+			buf.Replace(i.History.Next())
+			if i.History.Pos == i.History.Size() {
+				buf.Replace(currentLineBuf)
+			}
+		}
+	}
 
 	for {
 		// don't show placeholder when pasting unless we're in multiline mode
-		showPlaceholder := !i.Pasting || i.Prompt.UseAlt
+		showPlaceholder := (!i.Pasting || i.Prompt.UseAlt) && search == nil
 		if buf.IsEmpty() && showPlaceholder {
 			ph := i.Prompt.placeholder()
 			fmt.Print(
@@ -62,9 +109,24 @@ func (i *Instance) Readline() (string, error) { // nolint:gocyclo
 			)
 		}
 
-		r, err := i.Terminal.Read()
+		var r rune
+		var err error
+		if esc && v != nil {
+			var ok bool
+			r, ok, err = i.Terminal.ReadTimeout(escTimeout)
+			if err == nil && !ok {
+				// A lone Esc leaves the insert mode.
+				esc = false
+				if !v.normal {
+					v.enterNormal(buf)
+				}
+				continue
+			}
+		} else {
+			r, err = i.Terminal.Read()
+		}
 
-		if buf.IsEmpty() {
+		if buf.IsEmpty() && search == nil {
 			fmt.Print(ClearToEOL)
 		}
 
@@ -72,28 +134,93 @@ func (i *Instance) Readline() (string, error) { // nolint:gocyclo
 			return "", io.EOF
 		}
 
+		if menu != nil {
+			switch {
+			case r == CharTab, r == CharEsc, esc && r == CharEscapeEx,
+				escex && r == KeyBackTab:
+				// Cycling through the candidates.
+			case r == CharBell:
+				menu.cancel(buf)
+				menu = nil
+				continue
+			case r == CharEnter && menu.selected >= 0:
+				// Enter accepts the candidate without sending the line.
+				menu.close(buf)
+				menu = nil
+				continue
+			default:
+				menu.close(buf)
+				menu = nil
+			}
+		}
+
+		if ctrlX {
+			ctrlX = false
+			if r == CharLineEnd {
+				text, ok, err := i.edit(buf)
+				if err != nil {
+					return "", err
+				}
+				if ok {
+					return text, nil
+				}
+				continue
+			}
+		}
+
+		// Alt+Y arrives as Esc then y, both following the yank.
+		lastYank := yanking
+		if r != CharEsc {
+			yanking = false
+		}
+
+		if search != nil {
+			switch r {
+			case CharBckSearch, CharFwdSearch:
+				search.next(r == CharBckSearch)
+				search.render()
+				continue
+			case CharBackspace, CharCtrlH:
+				search.pop()
+				search.render()
+				continue
+			case CharInterrupt, CharBell:
+				// Cancel the search, going back to the original line.
+				search.clear()
+				buf = i.redraw(search.original)
+				search = nil
+				continue
+			default:
+				if r >= CharSpace {
+					search.add(r)
+					search.render()
+					continue
+				}
+
+				// Any other key accepts the match and is then handled
+				// as usual, so Enter sends it and arrows edit it.
+				search.clear()
+				buf = i.redraw(search.line())
+				search = nil
+			}
+		}
+
 		if escex {
 			escex = false
 
 			switch r {
 			case KeyUp:
-				if i.History.Pos > 0 {
-					if i.History.Pos == i.History.Size() {
-						currentLineBuf = []rune(buf.String())
-					}
-					buf.Replace(i.History.Prev())
-				}
+				historyPrev()
 			case KeyDown:
-				if i.History.Pos < i.History.Size() {
-					buf.Replace(i.History.Next())
-					if i.History.Pos == i.History.Size() {
-						buf.Replace(currentLineBuf)
-					}
-				}
+				historyNext()
 			case KeyLeft:
 				buf.MoveLeft()
 			case KeyRight:
 				buf.MoveRight()
+			case KeyBackTab:
+				if menu != nil {
+					menu.move(buf, -1)
+				}
 			case CharBracketedPaste:
 				var code string
 				for range 3 {
@@ -111,7 +238,7 @@ func (i *Instance) Readline() (string, error) { // nolint:gocyclo
 				}
 			case KeyDel:
 				if buf.DisplaySize() > 0 {
-					buf.Delete()
+					buf.DeleteChar()
 				}
 				metaDel = true
 			case MetaStart:
@@ -126,15 +253,58 @@ func (i *Instance) Readline() (string, error) { // nolint:gocyclo
 		} else if esc {
 			esc = false
 
-			switch r {
-			case 'b':
-				buf.MoveLeftWord()
-			case 'f':
-				buf.MoveRightWord()
-			case CharBackspace:
-				buf.DeleteWord()
-			case CharEscapeEx:
-				escex = true
+			if v != nil && r != CharEscapeEx {
+				// In vi mode, Esc leaves the insert mode and the key
+				// that follows is a command.
+				if !v.normal {
+					v.enterNormal(buf)
+				}
+			} else {
+				switch r {
+				case 'b':
+					buf.MoveLeftWord()
+				case 'f':
+					buf.MoveRightWord()
+				case 'd':
+					buf.KillWordForward()
+				case 'y':
+					if lastYank {
+						buf.YankPop()
+						yanking = true
+					}
+				case 'u':
+					buf.UpcaseWord()
+				case 'l':
+					buf.DowncaseWord()
+				case 'c':
+					buf.CapitalizeWord()
+				case CharBackspace:
+					buf.KillWord()
+				case CharEscapeEx:
+					escex = true
+				}
+				continue
+			}
+		}
+
+		if v != nil && v.normal && !i.Pasting && r >= CharSpace {
+			switch v.key(buf, r) {
+			case viActionHistoryPrev:
+				historyPrev()
+				buf.clampNormal()
+			case viActionHistoryNext:
+				historyNext()
+				buf.clampNormal()
+			case viActionEdit:
+				text, ok, err := i.edit(buf)
+				if err != nil {
+					return "", err
+				}
+				if ok {
+					return text, nil
+				}
+				buf.clampNormal()
+			case viActionNone:
 			}
 			continue
 		}
@@ -142,6 +312,14 @@ func (i *Instance) Readline() (string, error) { // nolint:gocyclo
 		switch r {
 		case CharNull:
 			continue
+		case CharBckSearch, CharFwdSearch:
+			search = newHistorySearch(
+				i.History,
+				[]rune(buf.String()),
+				r == CharBckSearch,
+			)
+			buf.Clear()
+			search.render()
 		case CharEsc:
 			esc = true
 		case CharInterrupt:
@@ -155,29 +333,47 @@ func (i *Instance) Readline() (string, error) { // nolint:gocyclo
 		case CharForward:
 			buf.MoveRight()
 		case CharBackspace, CharCtrlH:
-			buf.Remove()
+			buf.Backspace()
+			if v != nil {
+				v.record(CharBackspace)
+			}
 		case CharTab:
-			// todo: convert back to real tabs
-			for range 8 {
-				buf.Add(' ')
+			switch {
+			case i.Pasting:
+				// Pasted text keeps its tabs.
+				buf.Insert(r)
+			case menu != nil:
+				menu.move(buf, 1)
+			default:
+				menu = i.complete(buf)
 			}
 		case CharDelete:
 			if buf.DisplaySize() > 0 {
-				buf.Delete()
+				buf.DeleteChar()
 			} else {
 				return "", io.EOF
 			}
 		case CharKill:
-			buf.DeleteRemaining()
+			buf.KillRemaining()
 		case CharCtrlU:
-			buf.DeleteBefore()
+			buf.KillBefore()
 		case CharCtrlL:
 			buf.ClearScreen()
 		case CharCtrlW:
-			buf.DeleteWord()
+			buf.KillWord()
+		case CharCtrlY:
+			buf.Yank()
+			yanking = true
+		case CharTranspose:
+			buf.Transpose()
+		case CharCtrlX:
+			ctrlX = true
+		case CharCtrlUnder:
+			buf.Undo()
 		case CharEnter, CharCtrlJ:
 			output := buf.String()
 			if output != "" {
+				//nolint:errcheck
 				i.History.Add([]rune(output))
 			}
 			buf.MoveToEnd()
@@ -191,12 +387,185 @@ func (i *Instance) Readline() (string, error) { // nolint:gocyclo
 			}
 			if r >= CharSpace || r == CharEnter ||
 				r == CharCtrlJ {
-				buf.Add(r)
+				buf.Insert(r)
+				if v != nil {
+					v.record(r)
+				}
+			}
+		}
+	}
+}
+
+// readSecret reads a line without echoing it. Only deletion keys edit it,
+// and escape sequences, like those around pasted text, are skipped.
+func (i *Instance) readSecret() (string, error) {
+	var line []byte
+	for {
+		r, err := i.Terminal.Read()
+		if err != nil {
+			return "", io.EOF
+		}
+
+		switch r {
+		case CharEnter, CharCtrlJ:
+			fmt.Println()
+			return string(line), nil
+		case CharInterrupt:
+			fmt.Println()
+			return "", readline.ErrInterrupt
+		case CharDelete:
+			if len(line) == 0 {
+				return "", io.EOF
+			}
+		case CharBackspace, CharCtrlH:
+			_, size := utf8.DecodeLastRune(line)
+			line = line[:len(line)-size]
+		case CharCtrlU:
+			line = nil
+		case CharEsc:
+			if err := i.skipEscape(); err != nil {
+				return "", io.EOF
+			}
+		default:
+			// The terminal reads bytes, which are kept as they are to
+			// preserve multibyte characters.
+			if r >= CharSpace {
+				line = append(line, byte(r))
 			}
 		}
 	}
 }
 
+// skipEscape reads the rest of an escape sequence.
+func (i *Instance) skipEscape() error {
+	r, err := i.Terminal.Read()
+	if err != nil || r != CharEscapeEx {
+		return err
+	}
+
+	for {
+		r, err := i.Terminal.Read()
+		if err != nil {
+			return err
+		}
+		// The final byte of a control sequence.
+		if r >= 0x40 && r <= 0x7e {
+			return nil
+		}
+	}
+}
+
+// complete extends the word before the cursor to the longest prefix
+// shared by its candidates. When it cannot go further, it returns a menu
+// of the candidates.
+func (i *Instance) complete(buf *Buffer) *completionMenu {
+	line := string(buf.runes()[:buf.Pos])
+	word := lastWord(line)
+
+	var candidates []string
+	if i.Completer != nil {
+		candidates = i.Completer(line, word)
+	}
+	if len(candidates) == 0 {
+		candidates = historyWords(i.History, word)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	prefix := commonPrefix(candidates)
+	if len(candidates) == 1 && !strings.HasSuffix(prefix, "/") {
+		prefix += " "
+	}
+	if prefix != word && strings.HasPrefix(prefix, word) {
+		buf.checkpoint(editOther)
+		buf.replaceWord(len([]rune(word)), prefix)
+		return nil
+	}
+
+	menu := newCompletionMenu(word, candidates)
+	menu.show(buf)
+	return menu
+}
+
+func (i *Instance) setRawMode() error {
+	if i.Terminal.rawmode {
+		return nil
+	}
+
+	termios, err := readline.SetRawMode(os.Stdin.Fd())
+	if err != nil {
+		return fmt.Errorf("failed to set raw mode: %w", err)
+	}
+	i.Terminal.rawmode = true
+	i.Terminal.termios = termios
+
+	return nil
+}
+
+// edit opens the line in the editor, leaving the terminal to it while it
+// runs. It returns the edited text, or false when it is empty or the
+// editor failed, the line being drawn again to keep editing it.
+func (i *Instance) edit(buf *Buffer) (string, bool, error) {
+	if i.Editor == nil {
+		return "", false, nil
+	}
+
+	line := buf.String()
+	buf.MoveToEnd()
+	fmt.Print("\r\n")
+
+	//nolint:errcheck
+	readline.UnsetRawMode(os.Stdin.Fd(), i.Terminal.termios)
+	i.Terminal.Close()
+
+	text, editErr := i.Editor(line)
+
+	if err := i.Open(); err != nil {
+		return "", false, err
+	}
+	if err := i.setRawMode(); err != nil {
+		return "", false, err
+	}
+
+	text = strings.TrimRight(text, "\r\n")
+	if editErr != nil || strings.TrimSpace(text) == "" {
+		if editErr != nil {
+			fmt.Print(editErr.Error() + "\r\n")
+		}
+		*buf = *i.redraw([]rune(line))
+		return "", false, nil
+	}
+
+	// Show what is sent, as the editor cleared the screen.
+	fmt.Print(i.Prompt.prompt() + strings.ReplaceAll(text, "\n", "\r\n") + "\r\n")
+	//nolint:errcheck
+	i.History.Add([]rune(text))
+
+	return text, true, nil
+}
+
+// newBuffer returns an empty buffer sharing the kill ring of the
+// instance.
+func (i *Instance) newBuffer() *Buffer {
+	buf, _ := NewBuffer(i.Prompt)
+	buf.KillRing = i.killRing
+
+	return buf
+}
+
+// redraw prints the prompt and the line from the current cursor position
+// and returns the buffer holding the line.
+func (i *Instance) redraw(line []rune) *Buffer {
+	buf := i.newBuffer()
+	fmt.Print(i.Prompt.prompt())
+	for _, r := range line {
+		buf.Add(r)
+	}
+
+	return buf
+}
+
 func (i *Instance) HistoryEnable() {
 	i.History.Enabled = true
 }
@@ -205,6 +574,19 @@ func (i *Instance) HistoryDisable() {
 	i.History.Enabled = false
 }
 
+// Open attaches a new terminal to the instance. The terminal reads the
+// standard input until it is closed, so it is only kept open while a line
+// is read.
+func (i *Instance) Open() error {
+	term, err := NewTerminal()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrReadlineInit, err)
+	}
+	i.Terminal = term
+
+	return nil
+}
+
 func (i *Instance) Close() {
 	i.Terminal.Close()
 }
@@ -0,0 +1,262 @@
+package term
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+)
+
+const (
+	// defaultMarkdownWidth is used when the width of the terminal is
+	// unknown.
+	defaultMarkdownWidth = 80
+	// codeIndent indents the lines of code blocks.
+	codeIndent = "  "
+	// quotePrefix starts the lines of block quotes.
+	quotePrefix = "│ "
+)
+
+var (
+	headingPattern   = regexp.MustCompile(`^(#{1,6})\s+(.*?)(\s+#+)?\s*$`)
+	rulePattern      = regexp.MustCompile(`^ {0,3}((\*\s*){3,}|(-\s*){3,}|(_\s*){3,})$`)
+	fencePattern     = regexp.MustCompile("^\\s*(```+|~~~+)\\s*([\\w+#.-]*)")
+	listPattern      = regexp.MustCompile(`^(\s*)([-*+]|\d{1,9}[.)])\s+(.*)$`)
+	separatorPattern = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+)
+
+// headingStyles are the styles of the headings, by level.
+var headingStyles = []string{
+	StyleBold + StyleUnderline + ColorBlue,
+	StyleBold + ColorBlue,
+	StyleBold + ColorCyan,
+	StyleBold,
+	StyleBold,
+	StyleBold,
+}
+
+// bullets mark the items of lists, by level of nesting.
+var bullets = []string{"•", "◦", "▪"}
+
+// Markdown renders the Markdown of the answers for the terminal.
+type Markdown struct {
+	width int
+	// styled is unset when the output is not a terminal or colors are
+	// disabled, the text being printed as is.
+	styled bool
+}
+
+// NewMarkdown returns a renderer for the file. It renders plain text when
+// the file is not a terminal or when NO_COLOR is set.
+func NewMarkdown(f *os.File) *Markdown {
+	fd := int(f.Fd())
+	width := defaultMarkdownWidth
+	if w, _, err := term.GetSize(fd); err == nil && w > 0 {
+		width = w
+	}
+
+	return &Markdown{
+		width:  width,
+		styled: term.IsTerminal(fd) && os.Getenv("NO_COLOR") == "",
+	}
+}
+
+// Render returns the text formatted for the terminal.
+func (m *Markdown) Render(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if !m.styled {
+		return text
+	}
+
+	r := markdownRenderer{Markdown: m}
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if match := fencePattern.FindStringSubmatch(line); match != nil {
+			r.flush()
+			end := i + 1
+			for end < len(lines) && !isClosingFence(lines[end], match[1]) {
+				end++
+			}
+			r.code(match[2], lines[i+1:min(end, len(lines))])
+			i = end
+			continue
+		}
+
+		if i+1 < len(lines) && strings.Contains(line, "|") &&
+			strings.Contains(lines[i+1], "|") &&
+			separatorPattern.MatchString(lines[i+1]) {
+			r.flush()
+			end := i + 2
+			for end < len(lines) && strings.Contains(lines[end], "|") &&
+				strings.TrimSpace(lines[end]) != "" {
+				end++
+			}
+			r.table(lines[i], lines[i+1], lines[i+2:end])
+			i = end - 1
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			r.flush()
+			r.blank()
+		case headingPattern.MatchString(trimmed):
+			r.flush()
+			match := headingPattern.FindStringSubmatch(trimmed)
+			style := headingStyles[len(match[1])-1]
+			r.write("", "", parseInline(match[2], style))
+		case rulePattern.MatchString(line):
+			r.flush()
+			r.out = append(
+				r.out,
+				ColorGrey+strings.Repeat("─", m.width)+ColorDefault,
+			)
+		case strings.HasPrefix(trimmed, ">"):
+			text := strings.TrimSpace(strings.TrimLeft(trimmed, ">"))
+			if r.pending == nil || r.pending.prefix != quotePrefix {
+				r.flush()
+				r.pending = &block{prefix: quotePrefix, indent: quotePrefix}
+			}
+			r.pending.lines = append(r.pending.lines, text)
+		case listPattern.MatchString(line):
+			r.flush()
+			r.pending = listItem(listPattern.FindStringSubmatch(line))
+		default:
+			if r.pending == nil {
+				r.pending = &block{}
+			}
+			r.pending.lines = append(r.pending.lines, trimmed)
+		}
+	}
+	r.flush()
+
+	return r.String()
+}
+
+// block is a paragraph, a quote or a list item, whose lines are joined
+// and wrapped when the block ends.
+type block struct {
+	// prefix starts the first line, and indent the following ones.
+	prefix string
+	indent string
+	lines  []string
+}
+
+func listItem(match []string) *block {
+	level := len(strings.ReplaceAll(match[1], "\t", "    ")) / 2
+	indent := strings.Repeat("  ", level)
+
+	marker := match[2]
+	if marker == "-" || marker == "*" || marker == "+" {
+		marker = bullets[level%len(bullets)]
+	}
+
+	text := match[3]
+	switch {
+	case strings.HasPrefix(text, "[ ] "):
+		text = "☐ " + text[4:]
+	case strings.HasPrefix(text, "[x] "), strings.HasPrefix(text, "[X] "):
+		text = "☑ " + text[4:]
+	}
+
+	return &block{
+		prefix: indent + marker + " ",
+		indent: indent + strings.Repeat(" ", runewidth.StringWidth(marker)+1),
+		lines:  []string{text},
+	}
+}
+
+// markdownRenderer accumulates the rendered lines of a text.
+type markdownRenderer struct {
+	*Markdown
+	out     []string
+	pending *block
+}
+
+func (r *markdownRenderer) flush() {
+	if r.pending == nil {
+		return
+	}
+
+	b := r.pending
+	r.pending = nil
+	style := ""
+	if b.prefix == quotePrefix {
+		style = StyleItalic
+	}
+	r.write(
+		span{text: b.prefix, style: ColorGrey}.String(),
+		span{text: b.indent, style: ColorGrey}.String(),
+		parseInline(strings.Join(b.lines, " "), style),
+	)
+}
+
+// blank separates blocks by a single empty line.
+func (r *markdownRenderer) blank() {
+	if len(r.out) > 0 && r.out[len(r.out)-1] != "" {
+		r.out = append(r.out, "")
+	}
+}
+
+// write wraps the spans to the width, after the prefix on the first line
+// and the indent on the following ones. Both are as wide on the screen.
+func (r *markdownRenderer) write(prefix, indent string, spans []span) {
+	width := max(r.width-visibleWidth(prefix), 1)
+	for i, line := range wrapSpans(spans, width) {
+		if i == 0 {
+			r.out = append(r.out, prefix+line)
+		} else {
+			r.out = append(r.out, indent+line)
+		}
+	}
+}
+
+// code renders a fenced code block, highlighted when its language is
+// known. Code is not wrapped, to keep it as is.
+func (r *markdownRenderer) code(language string, lines []string) {
+	h := newHighlighter(language)
+	for _, line := range lines {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		if h != nil {
+			line = h.line(line)
+		}
+		r.out = append(r.out, codeIndent+line)
+	}
+}
+
+func (r *markdownRenderer) String() string {
+	out := r.out
+	for len(out) > 0 && out[0] == "" {
+		out = out[1:]
+	}
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// isClosingFence reports whether a line closes a fence opened by the
+// marker, made of at least as many of the same characters.
+func isClosingFence(line, marker string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, marker) &&
+		strings.Trim(line, marker[:1]) == ""
+}
+
+// visibleWidth is the width of a text on the screen, without its escape
+// sequences.
+func visibleWidth(s string) int {
+	return runewidth.StringWidth(stripEscapes(s))
+}
+
+var escapePattern = regexp.MustCompile("\x1b\\[[0-9;]*[A-Za-z]")
+
+func stripEscapes(s string) string {
+	return escapePattern.ReplaceAllString(s, "")
+}
@@ -0,0 +1,228 @@
+package term
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownRender(t *testing.T) {
+	t.Parallel()
+
+	m := &Markdown{width: 30, styled: true}
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name:     "Heading",
+			text:     "## Disk usage ##",
+			expected: "Disk usage",
+		},
+		{
+			name:     "Wrapped paragraph",
+			text:     "The largest directory is\nnode_modules, which takes most of the disk.",
+			expected: "The largest directory is\nnode_modules, which takes most\nof the disk.",
+		},
+		{
+			name:     "Emphasis and code",
+			text:     "Run **`du -sh`** on *every* snake_case_dir",
+			expected: "Run du -sh on every\nsnake_case_dir",
+		},
+		{
+			name:     "Links",
+			text:     "See [docs](https://x.io) or <https://y.io>",
+			expected: "See docs (https://x.io) or\nhttps://y.io",
+		},
+		{
+			name:     "Nested list",
+			text:     "- one\n  - two\n1. first item that is long enough to wrap\n- [x] done",
+			expected: "• one\n  ◦ two\n1. first item that is long\n   enough to wrap\n• ☑ done",
+		},
+		{
+			name:     "Quote",
+			text:     "> quoted\n> text",
+			expected: "│ quoted text",
+		},
+		{
+			name:     "Blank lines are collapsed",
+			text:     "\n\none\n\n\n\ntwo\n\n",
+			expected: "one\n\ntwo",
+		},
+		{
+			name:     "Fenced code is kept",
+			text:     "```\n  a  *b*\n```\nafter",
+			expected: "    a  *b*\nafter",
+		},
+		{
+			name:     "Unclosed fence",
+			text:     "~~~\ncode",
+			expected: "  code",
+		},
+		{
+			name:     "Escapes",
+			text:     `\*not emphasized\*`,
+			expected: "*not emphasized*",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := stripEscapes(m.Render(tt.text))
+			if got != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMarkdownRenderStyles(t *testing.T) {
+	t.Parallel()
+
+	m := &Markdown{width: 80, styled: true}
+
+	got := m.Render("**bold** _italic_ ~~gone~~ `code`")
+	for _, expected := range []string{
+		StyleBold + "bold" + ColorDefault,
+		StyleItalic + "italic" + ColorDefault,
+		StyleStrike + "gone" + ColorDefault,
+		colorCode + "code" + ColorDefault,
+	} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("Expected %q in %q", expected, got)
+		}
+	}
+}
+
+func TestMarkdownRenderPlain(t *testing.T) {
+	t.Parallel()
+
+	m := &Markdown{width: 80}
+
+	text := "# Title\n\n**bold** and `code`"
+	if got := m.Render(text); got != text {
+		t.Errorf("Expected the text as is, got %q", got)
+	}
+}
+
+func TestMarkdownRenderTable(t *testing.T) {
+	t.Parallel()
+
+	text := strings.Join([]string{
+		"| Name | Size | Kind |",
+		"|:-----|-----:|:----:|",
+		"| a | 1 | x |",
+		"| **long name** | 100 | \\| |",
+	}, "\n")
+
+	got := stripEscapes((&Markdown{width: 80, styled: true}).Render(text))
+	expected := strings.Join([]string{
+		"Name      │ Size │ Kind",
+		"──────────┼──────┼─────",
+		"a         │    1 │  x",
+		"long name │  100 │  |",
+	}, "\n")
+	if got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	// Wider than the terminal, the widest column is shrunk.
+	got = stripEscapes((&Markdown{width: 20, styled: true}).Render(text))
+	for _, line := range strings.Split(got, "\n") {
+		if w := visibleWidth(line); w > 20 {
+			t.Errorf("Expected lines of at most 20 columns, got %d: %q", w, line)
+		}
+	}
+	if !strings.Contains(got, "…") {
+		t.Errorf("Expected truncated cells, got:\n%s", got)
+	}
+}
+
+func TestSplitRow(t *testing.T) {
+	t.Parallel()
+
+	got := splitRow(`| a | b \| c |  |`)
+	expected := []string{"a", "b | c", ""}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		language string
+		lines    []string
+		expected []string
+	}{
+		{
+			name:     "Shell",
+			language: "bash",
+			lines:    []string{`echo "$HOME" $USER # done`},
+			expected: []string{
+				colorKeyword + "echo",
+				colorString + `"$HOME"`,
+				colorVariable + "$USER",
+				colorComment + "# done",
+			},
+		},
+		{
+			name:     "Shell number of arguments is not a comment",
+			language: "sh",
+			lines:    []string{"echo $#"},
+			expected: []string{colorVariable + "$" + ColorDefault + "#"},
+		},
+		{
+			name:     "Block comment across lines",
+			language: "go",
+			lines:    []string{"/* start", "end */ return 42"},
+			expected: []string{
+				colorComment + " start",
+				colorComment + "end */",
+				colorKeyword + "return",
+				colorNumber + "42",
+			},
+		},
+		{
+			name:     "SQL keywords in any case",
+			language: "sql",
+			lines:    []string{"SELECT name FROM users -- all"},
+			expected: []string{
+				colorKeyword + "SELECT",
+				colorKeyword + "FROM",
+				colorComment + "-- all",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := newHighlighter(tt.language)
+			var out []string
+			for _, line := range tt.lines {
+				out = append(out, h.line(line))
+			}
+			got := strings.Join(out, "\n")
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(got, expected) {
+					t.Errorf("Expected %q in %q", expected, got)
+				}
+			}
+			if stripEscapes(got) != strings.Join(tt.lines, "\n") {
+				t.Errorf("Expected the text to be kept, got %q", stripEscapes(got))
+			}
+		})
+	}
+
+	if newHighlighter("brainfuck") != nil {
+		t.Error("Expected no highlighter for an unknown language")
+	}
+}
@@ -0,0 +1,64 @@
+package term
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+)
+
+const progressBarWidth = 30
+
+// ProgressBar renders the progress of a multi-step download, redrawing a
+// single line per step.
+type ProgressBar struct {
+	w      io.Writer
+	status string
+}
+
+func NewProgressBar(w io.Writer) *ProgressBar {
+	return &ProgressBar{
+		w: w,
+	}
+}
+
+// Update redraws the current step. A new status ends the previous line.
+func (p *ProgressBar) Update(status string, completed, total int64) {
+	if p.status != "" && status != p.status {
+		fmt.Fprint(p.w, "\n")
+	}
+	p.status = status
+
+	fmt.Fprint(p.w, "\r"+ClearToEOL+renderProgress(status, completed, total))
+}
+
+// Done ends the line of the last step.
+func (p *ProgressBar) Done() {
+	if p.status != "" {
+		fmt.Fprint(p.w, "\n")
+	}
+	p.status = ""
+}
+
+func renderProgress(status string, completed, total int64) string {
+	if total <= 0 {
+		return status
+	}
+
+	completed = min(max(completed, 0), total)
+	filled := int(completed * progressBarWidth / total)
+	bar := strings.Repeat("=", filled)
+	if filled < progressBarWidth {
+		bar += ">" + strings.Repeat(" ", progressBarWidth-filled-1)
+	}
+
+	return fmt.Sprintf(
+		"%s %3d%% [%s] %s/%s",
+		status,
+		completed*100/total,
+		bar,
+		humanize.Bytes(uint64(completed)),
+		humanize.Bytes(uint64(total)),
+	)
+}
@@ -0,0 +1,83 @@
+package term
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderProgress(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		status    string
+		completed int64
+		total     int64
+		expected  string
+	}{
+		{
+			name:     "Unknown total",
+			status:   "pulling manifest",
+			expected: "pulling manifest",
+		},
+		{
+			name:      "Started",
+			status:    "pulling abc",
+			completed: 0,
+			total:     1000,
+			expected:  "pulling abc   0% [>                             ] 0 B/1.0 kB",
+		},
+		{
+			name:      "Half",
+			status:    "pulling abc",
+			completed: 500,
+			total:     1000,
+			expected:  "pulling abc  50% [===============>              ] 500 B/1.0 kB",
+		},
+		{
+			name:      "Complete",
+			status:    "pulling abc",
+			completed: 1000,
+			total:     1000,
+			expected:  "pulling abc 100% [==============================] 1.0 kB/1.0 kB",
+		},
+		{
+			name:      "Overflow",
+			status:    "pulling abc",
+			completed: 2000,
+			total:     1000,
+			expected:  "pulling abc 100% [==============================] 1.0 kB/1.0 kB",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := renderProgress(tt.status, tt.completed, tt.total)
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestProgressBarSteps(t *testing.T) {
+	t.Parallel()
+
+	w := &mockWriter{}
+	p := NewProgressBar(w)
+	p.Update("pulling manifest", 0, 0)
+	p.Update("pulling abc", 10, 100)
+	p.Update("pulling abc", 100, 100)
+	p.Update("success", 0, 0)
+	p.Done()
+
+	out := w.buf.String()
+	if got := strings.Count(out, "\n"); got != 3 {
+		t.Errorf("Expected 3 lines, got %d in %q", got, out)
+	}
+	if !strings.HasSuffix(out, "success\n") {
+		t.Errorf("Expected output to end with the last status, got %q", out)
+	}
+}
@@ -6,13 +6,16 @@ type Prompt struct {
 	Placeholder    string
 	AltPlaceholder string
 	UseAlt         bool
+	// Mode is an indicator of the edit mode shown before the prompt. Its
+	// width must not change while a line is read.
+	Mode string
 }
 
 func (p *Prompt) prompt() string {
 	if p.UseAlt {
-		return p.AltPrompt
+		return p.Mode + p.AltPrompt
 	}
-	return p.Prompt
+	return p.Mode + p.Prompt
 }
 
 func (p *Prompt) placeholder() string {
@@ -27,3 +27,20 @@ func PromptForBool(label string, defaultVal bool) bool {
 	}
 	return result == "Yes"
 }
+
+// PromptForSelect asks the user to pick one of the items and returns
+// its index.
+func PromptForSelect(label string, items []string) int {
+	prompt := promptui.Select{
+		Label:        label,
+		Items:        items,
+		HideHelp:     false,
+		HideSelected: false,
+	}
+	index, _, err := prompt.Run()
+	if err != nil {
+		fmt.Printf("Prompt failed: %v\n", err)
+		os.Exit(1)
+	}
+	return index
+}
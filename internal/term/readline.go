@@ -5,8 +5,6 @@ import (
 	"fmt"
 	"io"
 	"strings"
-
-	"github.com/ollama/ollama/readline"
 )
 
 var (
@@ -15,28 +13,32 @@ var (
 	ErrReadlineInit     = errors.New("error initializing readline")
 )
 
-func InitReadline(defaultValue string) (*Instance, error) {
-	prompt := Prompt{
-		Prompt:      defaultValue,
-		AltPrompt:   "...  ",
-		Placeholder: "Send a message (/help for help)",
+// NewInstance returns an input instance sharing the history across the
+// lines it reads. It must be opened before reading.
+func NewInstance(defaultValue string, history *History) *Instance {
+	return &Instance{
+		Prompt: &Prompt{
+			Prompt:      defaultValue,
+			AltPrompt:   "...  ",
+			Placeholder: "Send a message (/help for help)",
+		},
+		History:  history,
+		killRing: NewKillRing(),
 	}
+}
 
-	term, err := NewTerminal()
+func InitReadline(defaultValue string) (*Instance, error) {
+	history, err := NewHistory("", 0, nil)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrReadlineInit, err)
 	}
 
-	history, err := readline.NewHistory()
-	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrReadlineInit, err)
+	rl := NewInstance(defaultValue, history)
+	if err := rl.Open(); err != nil {
+		return nil, err
 	}
 
-	return &Instance{
-		Prompt:   &prompt,
-		Terminal: term,
-		History:  history,
-	}, nil
+	return rl, nil
 }
 
 type MultilineState int
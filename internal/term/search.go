@@ -0,0 +1,183 @@
+package term
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// historySearch is an incremental search of the history, started by
+// Ctrl+R (reverse) or Ctrl+S (forward).
+type historySearch struct {
+	history *History
+	query   []rune
+	// match is the index of the matching line in the history, or -1.
+	match   int
+	reverse bool
+	failed  bool
+	// original is the line being edited before the search started.
+	original []rune
+	buf      *Buffer
+}
+
+func newHistorySearch(
+	history *History,
+	original []rune,
+	reverse bool,
+) *historySearch {
+	return &historySearch{
+		history:  history,
+		match:    -1,
+		reverse:  reverse,
+		original: original,
+	}
+}
+
+// add appends a rune to the query, keeping the current match when it
+// still matches.
+func (s *historySearch) add(r rune) {
+	s.query = append(s.query, r)
+	s.find(s.start(), s.reverse)
+}
+
+// pop removes the last rune of the query and searches again from the end
+// of the history.
+func (s *historySearch) pop() {
+	if len(s.query) == 0 {
+		return
+	}
+
+	s.query = s.query[:len(s.query)-1]
+	s.match = -1
+	s.find(s.start(), s.reverse)
+}
+
+// next moves to the following match in the direction of the key.
+func (s *historySearch) next(reverse bool) {
+	s.reverse = reverse
+	from := s.start()
+	if s.match >= 0 {
+		from = s.match + 1
+		if reverse {
+			from = s.match - 1
+		}
+	}
+
+	s.find(from, reverse)
+}
+
+// start is where a search begins: the current match, or an end of the
+// history.
+func (s *historySearch) start() int {
+	switch {
+	case s.match >= 0:
+		return s.match
+	case s.reverse:
+		return s.history.Size() - 1
+	default:
+		return 0
+	}
+}
+
+func (s *historySearch) find(from int, reverse bool) {
+	if len(s.query) == 0 {
+		s.failed = false
+		return
+	}
+
+	i := findMatch(s.history.Lines(), string(s.query), from, reverse)
+	s.failed = i < 0
+	if !s.failed {
+		s.match = i
+	}
+}
+
+// line is the matching line, or the original one when nothing matched.
+func (s *historySearch) line() []rune {
+	if s.match < 0 {
+		return s.original
+	}
+
+	return []rune(s.history.Lines()[s.match])
+}
+
+func (s *historySearch) prompt() string {
+	name := "i-search"
+	if s.reverse {
+		name = "reverse-i-search"
+	}
+	if s.failed {
+		name = "failed " + name
+	}
+
+	return fmt.Sprintf("(%s)`%s': ", name, string(s.query))
+}
+
+// render draws the search prompt and the matching line in place of the
+// previous ones.
+func (s *historySearch) render() {
+	s.clear()
+
+	prompt := &Prompt{Prompt: s.prompt()}
+	s.buf, _ = NewBuffer(prompt)
+	fmt.Print(prompt.Prompt)
+	for _, r := range s.line() {
+		s.buf.Add(r)
+	}
+}
+
+// clear erases the search prompt.
+func (s *historySearch) clear() {
+	if s.buf != nil {
+		s.buf.Clear()
+	}
+}
+
+// findMatch returns the index of the nearest line matching the query,
+// starting at from and going backward when reverse is set, or -1. Lines
+// containing the query are preferred over lines matching it fuzzily.
+func findMatch(lines []string, query string, from int, reverse bool) int {
+	query = strings.ToLower(query)
+
+	for _, match := range []func(string) bool{
+		func(line string) bool {
+			return strings.Contains(strings.ToLower(line), query)
+		},
+		func(line string) bool {
+			return fuzzyMatch(line, query)
+		},
+	} {
+		for i := from; i >= 0 && i < len(lines); {
+			if match(lines[i]) {
+				return i
+			}
+			if reverse {
+				i--
+			} else {
+				i++
+			}
+		}
+	}
+
+	return -1
+}
+
+// fuzzyMatch reports whether the runes of the query appear in order in
+// the line, ignoring case.
+func fuzzyMatch(line, query string) bool {
+	q := []rune(query)
+	if len(q) == 0 {
+		return true
+	}
+
+	for _, r := range line {
+		if unicode.ToLower(r) == q[0] {
+			q = q[1:]
+			if len(q) == 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
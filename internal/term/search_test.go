@@ -0,0 +1,85 @@
+package term
+
+import "testing"
+
+func TestFindMatch(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{
+		"list the files in my home directory",
+		"show disk usage",
+		"List running docker containers",
+		"compress the logs",
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		from     int
+		reverse  bool
+		expected int
+	}{
+		{name: "Nearest reverse", query: "list", from: 3, reverse: true, expected: 2},
+		{name: "Older reverse", query: "list", from: 1, reverse: true, expected: 0},
+		{name: "Forward", query: "list", from: 1, expected: 2},
+		{name: "Fuzzy", query: "dsk usg", from: 3, reverse: true, expected: 1},
+		{name: "Substring first", query: "logs", from: 3, reverse: true, expected: 3},
+		{name: "No match", query: "kubernetes", from: 3, reverse: true, expected: -1},
+		{name: "Out of range", query: "list", from: 4, expected: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := findMatch(lines, tt.query, tt.from, tt.reverse)
+			if got != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestHistorySearch(t *testing.T) {
+	t.Parallel()
+
+	h, _ := NewHistory("", 0, nil)
+	for _, line := range []string{"git status", "go test ./...", "git push"} {
+		h.Add([]rune(line))
+	}
+
+	s := newHistorySearch(h, []rune("draft"), true)
+	if got := string(s.line()); got != "draft" {
+		t.Errorf("Expected the original line, got %q", got)
+	}
+
+	s.add('g')
+	s.add('i')
+	if got := string(s.line()); got != "git push" {
+		t.Errorf("Expected git push, got %q", got)
+	}
+
+	s.next(true)
+	if got := string(s.line()); got != "git status" {
+		t.Errorf("Expected git status, got %q", got)
+	}
+
+	s.next(true)
+	if !s.failed || string(s.line()) != "git status" {
+		t.Errorf("Expected a failed search keeping the match, got %q", string(s.line()))
+	}
+	if got := s.prompt(); got != "(failed reverse-i-search)`gi': " {
+		t.Errorf("Unexpected prompt %q", got)
+	}
+
+	s.next(false)
+	if got := string(s.line()); got != "git push" {
+		t.Errorf("Expected git push going forward, got %q", got)
+	}
+
+	s.pop()
+	s.pop()
+	if got := string(s.line()); got != "draft" || s.failed {
+		t.Errorf("Expected the original line with an empty query, got %q", got)
+	}
+}
@@ -0,0 +1,152 @@
+package term
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	// minColumnWidth is the width under which columns are not shrunk to
+	// fit the terminal.
+	minColumnWidth  = 3
+	columnSeparator = " │ "
+)
+
+type alignment int
+
+const (
+	alignLeft alignment = iota
+	alignCenter
+	alignRight
+)
+
+// table renders a table with aligned columns, shrinking the widest ones
+// when it is wider than the terminal.
+func (r *markdownRenderer) table(header, separator string, rows []string) {
+	cells := [][]string{splitRow(header)}
+	for _, row := range rows {
+		cells = append(cells, splitRow(row))
+	}
+
+	aligns := make([]alignment, len(cells[0]))
+	for i, spec := range splitRow(separator) {
+		if i >= len(aligns) {
+			break
+		}
+		switch {
+		case strings.HasPrefix(spec, ":") && strings.HasSuffix(spec, ":"):
+			aligns[i] = alignCenter
+		case strings.HasSuffix(spec, ":"):
+			aligns[i] = alignRight
+		}
+	}
+
+	// Cells are rendered first, their width being that of their text.
+	rendered := make([][][]span, len(cells))
+	widths := make([]int, len(aligns))
+	for i, row := range cells {
+		style := ""
+		if i == 0 {
+			style = StyleBold
+		}
+		rendered[i] = make([][]span, len(aligns))
+		for j := range aligns {
+			if j < len(row) {
+				rendered[i][j] = parseInline(row[j], style)
+			}
+			widths[j] = max(widths[j], runewidth.StringWidth(spansText(rendered[i][j])))
+		}
+	}
+	fitColumns(widths, r.width-runewidth.StringWidth(columnSeparator)*(len(widths)-1))
+
+	for i, row := range rendered {
+		line := make([]string, len(row))
+		for j, cell := range row {
+			line[j] = alignCell(cell, widths[j], aligns[j])
+		}
+		r.out = append(
+			r.out,
+			strings.TrimRight(strings.Join(line, ColorGrey+columnSeparator+ColorDefault), " "),
+		)
+
+		if i == 0 {
+			rules := make([]string, len(widths))
+			for j, w := range widths {
+				rules[j] = strings.Repeat("─", w)
+			}
+			r.out = append(
+				r.out,
+				ColorGrey+strings.Join(rules, "─┼─")+ColorDefault,
+			)
+		}
+	}
+}
+
+// fitColumns shrinks the widest columns until they fit in the width.
+func fitColumns(widths []int, width int) {
+	for {
+		total, widest := 0, 0
+		for i, w := range widths {
+			total += w
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+		if total <= width || widths[widest] <= minColumnWidth {
+			return
+		}
+		widths[widest]--
+	}
+}
+
+// alignCell pads a cell to the width of its column, truncating it when
+// the column was shrunk. Truncated cells lose their style.
+func alignCell(cell []span, width int, align alignment) string {
+	text := spansText(cell)
+	textWidth := runewidth.StringWidth(text)
+	rendered := renderSpans(cell)
+	if textWidth > width {
+		rendered = runewidth.Truncate(text, width, "…")
+		textWidth = runewidth.StringWidth(rendered)
+	}
+
+	padding := width - textWidth
+	switch align {
+	case alignRight:
+		return strings.Repeat(" ", padding) + rendered
+	case alignCenter:
+		return strings.Repeat(" ", padding/2) + rendered +
+			strings.Repeat(" ", padding-padding/2)
+	default:
+		return rendered + strings.Repeat(" ", padding)
+	}
+}
+
+// splitRow returns the cells of a row, split at the pipes that are not
+// escaped.
+func splitRow(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimPrefix(row, "|")
+	if strings.HasSuffix(row, "|") && !strings.HasSuffix(row, `\|`) {
+		row = row[:len(row)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	runes := []rune(row)
+	for i := 0; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\\' && i+1 < len(runes) && runes[i+1] == '|':
+			cell.WriteRune('|')
+			i++
+		case runes[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteRune(runes[i])
+		}
+	}
+
+	return append(cells, strings.TrimSpace(cell.String()))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
 	"github.com/muesli/cancelreader"
 )
@@ -11,6 +12,8 @@ import (
 type Terminal struct {
 	outchan chan rune
 	done    chan struct{}
+	// stopped is closed when ioloop returns.
+	stopped chan struct{}
 	rawmode bool
 	termios any
 	reader  cancelreader.CancelReader
@@ -24,6 +27,7 @@ func NewTerminal() (*Terminal, error) {
 	t := &Terminal{
 		outchan: make(chan rune),
 		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
 		rawmode: false,
 		termios: nil,
 		reader:  reader,
@@ -35,18 +39,33 @@ func NewTerminal() (*Terminal, error) {
 }
 
 func (t *Terminal) Read() (rune, error) {
-	r, ok := <-t.outchan
-	if !ok {
+	select {
+	case r := <-t.outchan:
+		return r, nil
+	case <-t.done:
 		return 0, io.EOF
 	}
+}
 
-	return r, nil
+// ReadTimeout reads a rune, reporting false when none arrives in time.
+func (t *Terminal) ReadTimeout(d time.Duration) (rune, bool, error) {
+	select {
+	case r := <-t.outchan:
+		return r, true, nil
+	case <-t.done:
+		return 0, false, io.EOF
+	case <-time.After(d):
+		return 0, false, nil
+	}
 }
 
 func (t *Terminal) Close() error {
-	t.reader.Close()
-	close(t.outchan)
 	close(t.done)
+	// Cancelling unblocks the pending read. Waiting for the loop to return
+	// frees the input for another reader, like an editor.
+	t.reader.Cancel()
+	<-t.stopped
+	t.reader.Close()
 	return nil
 }
 
@@ -60,6 +79,7 @@ func (t *Terminal) Closed() bool {
 }
 
 func (t *Terminal) ioloop() {
+	defer close(t.stopped)
 	// This is where we recover from panics
 	defer func() {
 		if rec := recover(); rec != nil {
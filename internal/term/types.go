@@ -26,22 +26,25 @@ const (
 	CharTranspose = 20
 	CharCtrlU     = 21
 	CharCtrlW     = 23
+	CharCtrlX     = 24
 	CharCtrlY     = 25
 	CharCtrlZ     = 26
 	CharEsc       = 27
+	CharCtrlUnder = 31
 	CharSpace     = 32
 	CharEscapeEx  = 91
 	CharBackspace = 127
 )
 
 const (
-	KeyDel    = 51
-	KeyUp     = 65
-	KeyDown   = 66
-	KeyRight  = 67
-	KeyLeft   = 68
-	MetaEnd   = 70
-	MetaStart = 72
+	KeyDel     = 51
+	KeyUp      = 65
+	KeyDown    = 66
+	KeyRight   = 67
+	KeyLeft    = 68
+	KeyBackTab = 90
+	MetaEnd    = 70
+	MetaStart  = 72
 )
 
 const (
@@ -56,12 +59,21 @@ const (
 	CursorShow = Esc + "[?25h"
 
 	ClearToEOL  = Esc + "[K"
+	ClearToEOS  = Esc + "[J"
 	ClearLine   = Esc + "[2K"
 	ClearScreen = Esc + "[2J"
 	CursorReset = Esc + "[0;0f"
 
 	ColorGrey    = Esc + "[38;5;245m"
 	ColorDefault = Esc + "[0m"
+	ColorReverse = Esc + "[7m"
+	ColorBlue    = Esc + "[38;5;75m"
+	ColorCyan    = Esc + "[38;5;80m"
+
+	StyleBold      = Esc + "[1m"
+	StyleItalic    = Esc + "[3m"
+	StyleUnderline = Esc + "[4m"
+	StyleStrike    = Esc + "[9m"
 
 	StartBracketedPaste = Esc + "[?2004h"
 	EndBracketedPaste   = Esc + "[?2004l"
@@ -0,0 +1,518 @@
+package term
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// EditMode is the set of key bindings of the input line.
+type EditMode int
+
+const (
+	EditModeEmacs EditMode = iota
+	EditModeVi
+)
+
+// ParseEditMode parses the name of an edit mode, emacs or vi.
+func ParseEditMode(name string) (EditMode, error) {
+	switch strings.ToLower(name) {
+	case "", "emacs":
+		return EditModeEmacs, nil
+	case "vi", "vim":
+		return EditModeVi, nil
+	default:
+		return EditModeEmacs, fmt.Errorf("unknown edit mode %q, use emacs or vi", name)
+	}
+}
+
+const (
+	viInsertIndicator = "[I] "
+	viNormalIndicator = "[N] "
+	// escTimeout tells a lone Esc, leaving the insert mode, from the start
+	// of an escape sequence such as an arrow key.
+	escTimeout = 50 * time.Millisecond
+)
+
+// viAction is what the line editor does after a key of the normal mode.
+type viAction int
+
+const (
+	viActionNone viAction = iota
+	viActionHistoryPrev
+	viActionHistoryNext
+	viActionEdit
+)
+
+// vi is the state of the vi mode while a line is read.
+type vi struct {
+	prompt *Prompt
+	normal bool
+	// operator is the d, c or y waiting for its motion.
+	operator rune
+	// find is the f, t, F or T waiting for its character.
+	find rune
+	// lastFind repeats the last character search with ; and ,.
+	lastFind     rune
+	lastFindChar rune
+
+	// keys are the keys of the change being typed, and last the keys of
+	// the last complete change, replayed by the dot.
+	keys      []rune
+	recording bool
+	last      []rune
+	replaying bool
+}
+
+func newVi(prompt *Prompt) *vi {
+	prompt.Mode = viInsertIndicator
+	return &vi{
+		prompt: prompt,
+	}
+}
+
+// enterNormal leaves the insert mode, moving back onto the last rune
+// typed like vi does.
+func (v *vi) enterNormal(buf *Buffer) {
+	v.normal = true
+	v.endChange()
+	buf.MoveLeft()
+	v.setIndicator(buf, viNormalIndicator)
+}
+
+func (v *vi) enterInsert(buf *Buffer) {
+	v.normal = false
+	v.setIndicator(buf, viInsertIndicator)
+}
+
+func (v *vi) setIndicator(buf *Buffer, indicator string) {
+	if v.prompt.Mode == indicator {
+		return
+	}
+
+	v.prompt.Mode = indicator
+	buf.RedrawPrompt()
+}
+
+// record adds a key typed in the insert mode to the change in progress.
+func (v *vi) record(r rune) {
+	if v.recording && !v.normal && !v.replaying {
+		v.keys = append(v.keys, r)
+	}
+}
+
+func (v *vi) startChange(r rune) {
+	if v.replaying {
+		return
+	}
+
+	v.recording = true
+	v.keys = []rune{r}
+}
+
+func (v *vi) continueChange(r rune) {
+	if v.recording && !v.replaying {
+		v.keys = append(v.keys, r)
+	}
+}
+
+func (v *vi) endChange() {
+	if v.recording && !v.replaying {
+		v.last = v.keys
+	}
+	v.recording = false
+}
+
+// cancel drops the pending operator and character search.
+func (v *vi) cancel() {
+	v.operator = 0
+	v.find = 0
+	if !v.replaying {
+		v.recording = false
+	}
+}
+
+// repeat replays the last change.
+func (v *vi) repeat(buf *Buffer) {
+	if len(v.last) == 0 {
+		return
+	}
+
+	v.replaying = true
+	for _, r := range v.last {
+		switch {
+		case v.normal:
+			v.key(buf, r)
+		case r == CharBackspace:
+			buf.Backspace()
+		default:
+			buf.Insert(r)
+		}
+	}
+	if !v.normal {
+		v.enterNormal(buf)
+	}
+	v.replaying = false
+}
+
+// key handles a key of the normal mode.
+func (v *vi) key(buf *Buffer, r rune) viAction { // nolint:gocyclo
+	line := buf.runes()
+
+	if v.find != 0 {
+		find := v.find
+		v.find = 0
+		v.lastFind, v.lastFindChar = find, r
+		v.continueChange(r)
+
+		target, ok := findChar(line, buf.Pos, find, r)
+		if !ok {
+			v.cancel()
+			return viActionNone
+		}
+		v.motion(buf, target, find == 'f' || find == 't')
+		return viActionNone
+	}
+
+	switch r {
+	case 'f', 't', 'F', 'T':
+		v.find = r
+		v.continueChange(r)
+		return viActionNone
+	case ';', ',':
+		if v.lastFind == 0 {
+			return viActionNone
+		}
+		find := v.lastFind
+		if r == ',' {
+			find = reverseFind(find)
+		}
+		from := buf.Pos
+		// Repeating t or T must skip the match next to the cursor.
+		if find == 't' && from+1 < len(line) && line[from+1] == v.lastFindChar {
+			from++
+		}
+		if find == 'T' && from > 0 && line[from-1] == v.lastFindChar {
+			from--
+		}
+		v.continueChange(r)
+		if target, ok := findChar(line, from, find, v.lastFindChar); ok {
+			v.motion(buf, target, find == 'f' || find == 't')
+		} else {
+			v.cancel()
+		}
+		return viActionNone
+	}
+
+	if target, inclusive, ok := viMotion(line, buf.Pos, r, v.operator); ok {
+		v.continueChange(r)
+		v.motion(buf, target, inclusive)
+		return viActionNone
+	}
+
+	if v.operator != 0 {
+		// Doubling the operator, like dd, applies it to the line.
+		if r == v.operator {
+			v.continueChange(r)
+			buf.moveTo(0)
+			v.apply(buf, 0, len(line))
+		} else {
+			v.cancel()
+		}
+		return viActionNone
+	}
+
+	switch r {
+	case 'i':
+		v.startChange(r)
+		v.enterInsert(buf)
+	case 'a':
+		v.startChange(r)
+		buf.MoveRight()
+		v.enterInsert(buf)
+	case 'I':
+		v.startChange(r)
+		buf.moveTo(firstNonSpace(line))
+		v.enterInsert(buf)
+	case 'A':
+		v.startChange(r)
+		buf.MoveToEnd()
+		v.enterInsert(buf)
+	case 'd', 'c':
+		v.startChange(r)
+		v.operator = r
+	case 'y':
+		v.operator = r
+	case 'D', 'C':
+		v.startChange(r)
+		v.operator = unicode.ToLower(r)
+		v.apply(buf, buf.Pos, len(line))
+	case 'x':
+		if buf.Pos < len(line) {
+			v.startChange(r)
+			v.operator = 'd'
+			v.apply(buf, buf.Pos, buf.Pos+1)
+		}
+	case 'p', 'P':
+		v.put(buf, r)
+	case 'u':
+		buf.Undo()
+		buf.clampNormal()
+	case '.':
+		v.repeat(buf)
+	case 'k':
+		return viActionHistoryPrev
+	case 'j':
+		return viActionHistoryNext
+	case 'v':
+		return viActionEdit
+	}
+
+	return viActionNone
+}
+
+// motion moves the cursor, or applies the pending operator between the
+// cursor and the target.
+func (v *vi) motion(buf *Buffer, target int, inclusive bool) {
+	if v.operator == 0 {
+		buf.moveTo(target)
+		buf.clampNormal()
+		return
+	}
+
+	from, to := buf.Pos, target
+	if to < from {
+		from, to = to, from
+	}
+	if inclusive {
+		to++
+	}
+	to = min(to, buf.Buf.Size())
+
+	v.apply(buf, from, to)
+}
+
+// apply runs the pending operator on the runes from..to.
+func (v *vi) apply(buf *Buffer, from, to int) {
+	operator := v.operator
+	v.operator = 0
+
+	line := buf.runes()
+	if buf.KillRing != nil {
+		buf.KillRing.Push(string(line[from:to]))
+	}
+
+	switch operator {
+	case 'y':
+		buf.moveTo(from)
+	case 'd':
+		buf.deleteRange(from, to)
+		buf.clampNormal()
+		v.endChange()
+	case 'c':
+		buf.deleteRange(from, to)
+		v.enterInsert(buf)
+	}
+}
+
+// put pastes the last yanked or deleted text after the cursor with p, or
+// before it with P.
+func (v *vi) put(buf *Buffer, r rune) {
+	if buf.KillRing == nil {
+		return
+	}
+
+	text := buf.KillRing.Top()
+	if text == "" {
+		return
+	}
+
+	v.startChange(r)
+	buf.checkpoint(editOther)
+	if r == 'p' && buf.Pos < buf.Buf.Size() {
+		buf.MoveRight()
+	}
+	for _, r := range text {
+		buf.Add(r)
+	}
+	buf.MoveLeft()
+	v.endChange()
+}
+
+// viMotion returns the target of a motion key. Inclusive motions, like e
+// and $, include the target when an operator applies.
+func viMotion(line []rune, pos int, r rune, operator rune) (int, bool, bool) {
+	switch r {
+	case 'h', CharBackspace:
+		return max(pos-1, 0), false, true
+	case 'l', ' ':
+		return min(pos+1, len(line)), false, true
+	case '0':
+		return 0, false, true
+	case '^':
+		return firstNonSpace(line), false, true
+	case '$':
+		return max(len(line)-1, 0), true, true
+	case 'w':
+		// Like vi, cw changes the end of the word and not the blanks
+		// after it.
+		if operator == 'c' && pos < len(line) && !unicode.IsSpace(line[pos]) {
+			return wordEndVi(line, pos, true), true, true
+		}
+		return nextWordStart(line, pos), false, true
+	case 'e':
+		return wordEndVi(line, pos, false), true, true
+	case 'b':
+		return prevWordStart(line, pos), false, true
+	default:
+		return 0, false, false
+	}
+}
+
+// runeClass tells apart blanks, word characters and punctuation, the
+// boundaries of the vi word motions.
+func runeClass(r rune) int {
+	switch {
+	case unicode.IsSpace(r):
+		return 0
+	case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+		return 1
+	default:
+		return 2
+	}
+}
+
+func nextWordStart(line []rune, pos int) int {
+	if pos >= len(line) {
+		return len(line)
+	}
+
+	class := runeClass(line[pos])
+	for pos < len(line) && class != 0 && runeClass(line[pos]) == class {
+		pos++
+	}
+	for pos < len(line) && runeClass(line[pos]) == 0 {
+		pos++
+	}
+
+	return pos
+}
+
+// wordEndVi returns the last rune of the word after pos, or of the word
+// under the cursor when current is set.
+func wordEndVi(line []rune, pos int, current bool) int {
+	if len(line) == 0 {
+		return 0
+	}
+	if !current {
+		pos++
+	}
+	for pos < len(line) && runeClass(line[pos]) == 0 {
+		pos++
+	}
+	if pos >= len(line) {
+		return len(line) - 1
+	}
+
+	class := runeClass(line[pos])
+	for pos+1 < len(line) && runeClass(line[pos+1]) == class {
+		pos++
+	}
+
+	return pos
+}
+
+func prevWordStart(line []rune, pos int) int {
+	pos--
+	for pos > 0 && runeClass(line[pos]) == 0 {
+		pos--
+	}
+	if pos <= 0 {
+		return 0
+	}
+
+	class := runeClass(line[pos])
+	for pos > 0 && runeClass(line[pos-1]) == class {
+		pos--
+	}
+
+	return pos
+}
+
+func firstNonSpace(line []rune) int {
+	for i, r := range line {
+		if !unicode.IsSpace(r) {
+			return i
+		}
+	}
+
+	return 0
+}
+
+// findChar returns the target of f, t, F or T followed by c.
+func findChar(line []rune, pos int, find, c rune) (int, bool) {
+	switch find {
+	case 'f', 't':
+		for i := pos + 1; i < len(line); i++ {
+			if line[i] == c {
+				if find == 't' {
+					return i - 1, true
+				}
+				return i, true
+			}
+		}
+	case 'F', 'T':
+		for i := min(pos, len(line)) - 1; i >= 0; i-- {
+			if line[i] == c {
+				if find == 'T' {
+					return i + 1, true
+				}
+				return i, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+func reverseFind(find rune) rune {
+	switch find {
+	case 'f':
+		return 'F'
+	case 'F':
+		return 'f'
+	case 't':
+		return 'T'
+	default:
+		return 't'
+	}
+}
+
+// moveTo moves the cursor to a position of the line.
+func (b *Buffer) moveTo(pos int) {
+	pos = max(0, min(pos, b.Buf.Size()))
+	for b.Pos > pos {
+		b.MoveLeft()
+	}
+	for b.Pos < pos {
+		b.MoveRight()
+	}
+}
+
+// deleteRange deletes the runes from..to and leaves the cursor at from.
+func (b *Buffer) deleteRange(from, to int) {
+	b.checkpoint(editOther)
+	b.moveTo(from)
+	for range to - from {
+		b.Delete()
+	}
+}
+
+// clampNormal keeps the cursor on a rune, as the normal mode of vi never
+// places it after the end of the line.
+func (b *Buffer) clampNormal() {
+	if b.Buf.Size() > 0 && b.Pos >= b.Buf.Size() {
+		b.MoveLeft()
+	}
+}
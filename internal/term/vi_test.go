@@ -0,0 +1,122 @@
+package term
+
+import "testing"
+
+func TestViMotions(t *testing.T) {
+	t.Parallel()
+
+	line := []rune("git commit -m fix")
+
+	tests := []struct {
+		name     string
+		key      rune
+		pos      int
+		expected int
+	}{
+		{name: "Next word", key: 'w', pos: 0, expected: 4},
+		{name: "Next word over punctuation", key: 'w', pos: 4, expected: 11},
+		{name: "End of word", key: 'e', pos: 0, expected: 2},
+		{name: "End of next word", key: 'e', pos: 2, expected: 9},
+		{name: "Previous word", key: 'b', pos: 11, expected: 4},
+		{name: "Start of line", key: '0', pos: 7, expected: 0},
+		{name: "End of line", key: '$', pos: 0, expected: 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, _, ok := viMotion(line, tt.pos, tt.key, 0)
+			if !ok || got != tt.expected {
+				t.Errorf("Expected %d, got %d (%v)", tt.expected, got, ok)
+			}
+		})
+	}
+}
+
+func TestFindChar(t *testing.T) {
+	t.Parallel()
+
+	line := []rune("a,b,c")
+	if got, ok := findChar(line, 0, 'f', ','); !ok || got != 1 {
+		t.Errorf("Expected f to find 1, got %d", got)
+	}
+	if got, ok := findChar(line, 0, 't', 'c'); !ok || got != 3 {
+		t.Errorf("Expected t to stop at 3, got %d", got)
+	}
+	if got, ok := findChar(line, 4, 'F', ','); !ok || got != 3 {
+		t.Errorf("Expected F to find 3, got %d", got)
+	}
+	if _, ok := findChar(line, 0, 'f', 'z'); ok {
+		t.Errorf("Expected no match")
+	}
+}
+
+func TestViKeys(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		line     string
+		keys     string
+		expected string
+	}{
+		{name: "Delete word", line: "hello big world", keys: "0wdw", expected: "hello world"},
+		{name: "Change word", line: "hello big world", keys: "bcwthere\x1b", expected: "hello big there"},
+		{name: "Repeat", line: "a-b-c", keys: "0x.", expected: "b-c"},
+		{name: "Delete to char and put", line: "foo bar", keys: "0dtr$p", expected: "rfoo ba"},
+		{name: "Repeat change", line: "one two", keys: "0cwONE\x1bw.", expected: "ONE ONE"},
+		{name: "Yank and put", line: "ab", keys: "0yl$p", expected: "aba"},
+		{name: "Delete line", line: "some text", keys: "dd", expected: ""},
+		{name: "Change to end", line: "keep drop", keys: "0wCgo\x1b", expected: "keep go"},
+		{name: "Undo", line: "abc", keys: "xu", expected: "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			prompt := &Prompt{Prompt: ">>> "}
+			buf, err := NewBuffer(prompt)
+			if err != nil {
+				t.Fatalf("Failed to create buffer: %v", err)
+			}
+			buf.KillRing = NewKillRing()
+			for _, r := range tt.line {
+				buf.Insert(r)
+			}
+
+			v := newVi(prompt)
+			v.enterNormal(buf)
+			for _, r := range tt.keys {
+				switch {
+				case r == CharEsc:
+					v.enterNormal(buf)
+				case v.normal:
+					v.key(buf, r)
+				default:
+					buf.Insert(r)
+					v.record(r)
+				}
+			}
+
+			if got := buf.String(); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseEditMode(t *testing.T) {
+	t.Parallel()
+
+	if mode, err := ParseEditMode("vi"); err != nil || mode != EditModeVi {
+		t.Errorf("Expected vi mode, got %v %v", mode, err)
+	}
+	if mode, err := ParseEditMode(""); err != nil || mode != EditModeEmacs {
+		t.Errorf("Expected emacs mode by default, got %v %v", mode, err)
+	}
+	if _, err := ParseEditMode("nano"); err == nil {
+		t.Errorf("Expected an error for unknown modes")
+	}
+}
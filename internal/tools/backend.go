@@ -9,11 +9,13 @@ import (
 	"github.com/nullswan/llama-hackaton/internal/chat"
 	"github.com/nullswan/llama-hackaton/internal/completion"
 	"github.com/nullswan/llama-hackaton/internal/llama"
+	"github.com/nullswan/llama-hackaton/internal/redact"
 )
 
 type TextToJSONBackend struct {
-	backend *llama.TextToJSONProvider
-	logger  *slog.Logger
+	backend  *llama.TextToJSONProvider
+	logger   *slog.Logger
+	redactor *redact.Redactor
 }
 
 func NewTextToJSONBackend(
@@ -26,11 +28,50 @@ func NewTextToJSONBackend(
 	}
 }
 
+// WithRedactor returns a backend that strips secrets from the conversation
+// before it is sent to the provider.
+func (t TextToJSONBackend) WithRedactor(
+	redactor *redact.Redactor,
+) TextToJSONBackend {
+	t.redactor = redactor
+	return t
+}
+
+// WithBackend returns a backend sending completions to another provider.
+func (t TextToJSONBackend) WithBackend(
+	backend *llama.TextToJSONProvider,
+) TextToJSONBackend {
+	t.backend = backend
+	return t
+}
+
+// WithOptions returns a backend generating with other options.
+func (t TextToJSONBackend) WithOptions(
+	options llama.GenerationOptions,
+) TextToJSONBackend {
+	t.backend = t.backend.WithOptions(options)
+	return t
+}
+
+// Backend returns the provider completions are sent to.
+func (t TextToJSONBackend) Backend() *llama.TextToJSONProvider {
+	return t.backend
+}
+
+func (t TextToJSONBackend) GetModel() string {
+	return t.backend.GetModel()
+}
+
+// Warmup loads the model of the provider in memory.
+func (t TextToJSONBackend) Warmup(ctx context.Context) error {
+	return t.backend.Warmup(ctx)
+}
+
 func (t TextToJSONBackend) Do(
 	ctx context.Context,
 	conversation *chat.Conversation,
 ) (string, error) {
-	messages := conversation.GetMessages()
+	messages := t.redactMessages(conversation.GetMessages())
 
 	outCh := make(chan completion.Completion)
 	go func() {
@@ -51,8 +92,40 @@ func (t TextToJSONBackend) Do(
 		}
 
 		content := strings.ReplaceAll(cmpl.Content(), "```json", "")
-		return strings.ReplaceAll(content, "```", ""), nil
+		content = strings.ReplaceAll(content, "```", "")
+		return content, nil
 	}
 
 	return "", errors.New("completion channel closed")
 }
+
+// Restore replaces the placeholders of the secrets in a text decoded from
+// a completion. Completions keep their placeholders, as secrets holding
+// quotes or newlines would break their JSON.
+func (t TextToJSONBackend) Restore(s string) string {
+	if t.redactor == nil {
+		return s
+	}
+
+	return t.redactor.Restore(s)
+}
+
+// redactMessages returns a copy of the messages with secrets replaced.
+// System prompts are authored by us and are left untouched.
+func (t TextToJSONBackend) redactMessages(
+	messages []chat.Message,
+) []chat.Message {
+	if t.redactor == nil {
+		return messages
+	}
+
+	redacted := make([]chat.Message, len(messages))
+	for i, m := range messages {
+		if m.Role != chat.RoleSystem {
+			m.Content = t.redactor.Redact(m.Content)
+		}
+		redacted[i] = m
+	}
+
+	return redacted
+}
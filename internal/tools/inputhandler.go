@@ -11,26 +11,88 @@ import (
 
 type InputHandler interface {
 	Read(ctx context.Context, defaultValue string) (string, error)
+	// WithCompleter returns a handler completing the input on tab.
+	WithCompleter(completer term.Completer) InputHandler
+	// WithEditMode returns a handler using the key bindings of the mode.
+	WithEditMode(mode term.EditMode) InputHandler
+	// WithEditor returns a handler editing the input in an external editor
+	// on Ctrl+X Ctrl+E.
+	WithEditor(editor term.Editor) InputHandler
+	// WithoutHistory returns a handler that neither recalls nor remembers
+	// the lines read, for answers that may be secrets.
+	WithoutHistory() InputHandler
+	// WithoutEcho returns a handler that does not show the lines read,
+	// like passwords. They are not remembered either.
+	WithoutEcho() InputHandler
 }
 
 type inputHandler struct {
-	logger *slog.Logger
+	logger    *slog.Logger
+	completer term.Completer
+	editMode  term.EditMode
+	editor    term.Editor
+	history   *term.History
+	secret    bool
+	// rl lives as long as the session, keeping the history between
+	// prompts.
+	rl *term.Instance
 }
 
 func NewInputHandler(
 	logger *slog.Logger,
+	history *term.History,
 ) InputHandler {
 	return &inputHandler{
-		logger: logger,
+		logger:  logger,
+		history: history,
+		rl:      term.NewInstance("", history),
 	}
 }
 
+func (i *inputHandler) WithCompleter(completer term.Completer) InputHandler {
+	handler := *i
+	handler.completer = completer
+	return &handler
+}
+
+func (i *inputHandler) WithEditMode(mode term.EditMode) InputHandler {
+	handler := *i
+	handler.editMode = mode
+	return &handler
+}
+
+func (i *inputHandler) WithEditor(editor term.Editor) InputHandler {
+	handler := *i
+	handler.editor = editor
+	return &handler
+}
+
+func (i *inputHandler) WithoutHistory() InputHandler {
+	handler := *i
+	// The history is kept in memory and disabled, so it stays empty.
+	handler.history, _ = term.NewHistory("", 0, nil)
+	handler.history.Enabled = false
+	return &handler
+}
+
+func (i *inputHandler) WithoutEcho() InputHandler {
+	handler := *i
+	handler.secret = true
+	return &handler
+}
+
 func (i *inputHandler) Read(
 	ctx context.Context,
 	defaultValue string,
 ) (string, error) {
-	rl, err := term.InitReadline(defaultValue)
-	if err != nil {
+	rl := i.rl
+	rl.Prompt.Prompt = defaultValue
+	rl.History = i.history
+	rl.Completer = i.completer
+	rl.EditMode = i.editMode
+	rl.Editor = i.editor
+	rl.Secret = i.secret
+	if err := rl.Open(); err != nil {
 		return "", fmt.Errorf("error initializing readline: %w", err)
 	}
 
@@ -0,0 +1,149 @@
+//go:build !windows
+
+package tools
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/nullswan/llama-hackaton/internal/term"
+)
+
+// TestInputHandlerWithoutHistory types a request, then an answer to a
+// script prompt, on a pseudo-terminal standing for the user terminal.
+// Only the request reaches the history file.
+func TestInputHandlerWithoutHistory(t *testing.T) {
+	ptmx, _ := openTerminal(t)
+
+	path := filepath.Join(t.TempDir(), "history")
+	history, err := term.NewHistory(path, 10, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	handler := NewInputHandler(testLogger(), history)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, tc := range []struct {
+		handler InputHandler
+		line    string
+	}{
+		{handler, "list my files"},
+		{handler.WithoutHistory(), "hunter2"},
+	} {
+		//nolint:errcheck
+		ptmx.WriteString(tc.line + "\r")
+		got, err := tc.handler.Read(ctx, ">>> ")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got != tc.line {
+			t.Fatalf("Expected %q, got %q", tc.line, got)
+		}
+	}
+
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected history file, got %v", err)
+	}
+	if !strings.Contains(string(saved), "list my files") {
+		t.Errorf("Expected the request in the history, got %q", saved)
+	}
+	if strings.Contains(string(saved), "hunter2") {
+		t.Errorf("Expected no script answer in the history, got %q", saved)
+	}
+	for _, line := range history.Lines() {
+		if line == "hunter2" {
+			t.Errorf("Expected no script answer in the history, got %q", line)
+		}
+	}
+}
+
+func TestInputHandlerWithoutEcho(t *testing.T) {
+	ptmx, output := openTerminal(t)
+
+	history, _ := term.NewHistory("", 10, nil)
+	handler := NewInputHandler(testLogger(), history).WithoutEcho()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Typing starts once the prompt is shown, the terminal echoing what
+	// is typed before.
+	go func() {
+		for !strings.Contains(output(), "Password: ") {
+			time.Sleep(10 * time.Millisecond)
+		}
+		//nolint:errcheck
+		ptmx.WriteString("hunter22\x7f\r")
+	}()
+	got, err := handler.Read(ctx, "Password: ")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got != "hunter2" {
+		t.Errorf("Expected %q, got %q", "hunter2", got)
+	}
+
+	// Let the terminal deliver what was printed.
+	time.Sleep(50 * time.Millisecond)
+	if shown := output(); strings.Contains(shown, "hunter") {
+		t.Errorf("Expected no echo of the answer, got %q", shown)
+	}
+	if history.Size() != 0 {
+		t.Errorf("Expected an empty history, got %v", history.Lines())
+	}
+}
+
+// openTerminal makes a pseudo-terminal the standard input and output of
+// the test. It returns its master side and a function returning what was
+// printed so far.
+func openTerminal(t *testing.T) (*os.File, func() string) {
+	t.Helper()
+
+	ptmx, tty, err := pty.Open()
+	if err != nil {
+		t.Skipf("Pseudo-terminals are not available: %v", err)
+	}
+
+	var mu sync.Mutex
+	var printed strings.Builder
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := ptmx.Read(buf)
+			mu.Lock()
+			printed.Write(buf[:n])
+			mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	stdin, stdout := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = tty, tty
+	t.Cleanup(func() {
+		os.Stdin, os.Stdout = stdin, stdout
+		tty.Close()
+		ptmx.Close()
+	})
+
+	return ptmx, func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return printed.String()
+	}
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ModelRouter picks between a fast model, answering questions and simple
+// requests, and a strong one used for code generation and retries.
+type ModelRouter struct {
+	fast   TextToJSONBackend
+	strong TextToJSONBackend
+}
+
+func NewModelRouter(fast, strong TextToJSONBackend) ModelRouter {
+	return ModelRouter{
+		fast:   fast,
+		strong: strong,
+	}
+}
+
+func (r ModelRouter) Fast() TextToJSONBackend {
+	return r.fast
+}
+
+func (r ModelRouter) Strong() TextToJSONBackend {
+	return r.strong
+}
+
+// Routed reports whether the fast and strong models differ.
+func (r ModelRouter) Routed() bool {
+	return r.fast.GetModel() != r.strong.GetModel()
+}
+
+// Warmup loads both models in memory concurrently.
+func (r ModelRouter) Warmup(ctx context.Context) error {
+	backends := []TextToJSONBackend{r.strong}
+	if r.Routed() {
+		backends = append(backends, r.fast)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(backends))
+	for i, b := range backends {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := b.Warmup(ctx); err != nil {
+				errs[i] = fmt.Errorf("error warming up %s: %w", b.GetModel(), err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
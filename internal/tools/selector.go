@@ -4,6 +4,7 @@ import "github.com/nullswan/llama-hackaton/internal/term"
 
 type Selector interface {
 	SelectBool(title string, defaultValue bool) bool
+	Select(title string, items []string) int
 }
 
 type selector struct{}
@@ -15,3 +16,7 @@ func NewSelector() Selector {
 func (s *selector) SelectBool(title string, defaultValue bool) bool {
 	return term.PromptForBool(title, defaultValue)
 }
+
+func (s *selector) Select(title string, items []string) int {
+	return term.PromptForSelect(title, items)
+}